@@ -3,6 +3,7 @@ package ioseq
 import (
 	"bytes"
 	"encoding/base64"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -52,7 +53,8 @@ func benchmarkPipe(b *testing.B, f func(w io.Writer) io.WriteCloser) {
 			buf := make([]byte, 8192)
 			for range b.N {
 				if _, err := w.Write(buf); err != nil {
-					b.Fatal(err)
+					b.Error(err)
+					return
 				}
 			}
 		}()
@@ -246,3 +248,30 @@ func fillByte(b []byte, value byte) {
 		copy(b[i:], b[:i])
 	}
 }
+
+func BenchmarkReaderFromSeqSmallReads(b *testing.B) {
+	for _, size := range []int{1, 16, 512} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			b.SetBytes(8192)
+			r := ReaderFromSeq(produceAndWork(b, noop))
+			defer r.Close()
+			buf := make([]byte, size)
+			for {
+				if _, err := r.Read(buf); err != nil {
+					break
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReaderFromSeqReadByte(b *testing.B) {
+	b.SetBytes(8192)
+	r := ReaderFromSeq(produceAndWork(b, noop)).(io.ByteReader)
+	defer r.(io.Closer).Close()
+	for {
+		if _, err := r.ReadByte(); err != nil {
+			break
+		}
+	}
+}
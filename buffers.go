@@ -0,0 +1,59 @@
+package ioseq
+
+import "io"
+
+// BuffersWriter is implemented by writers that can write several
+// buffers in one call. The writers returned by [SeqWriter] and
+// [SyncSeqWriter] and [*BufferedSeqWriter] implement it.
+//
+// Note that a [net.Buffers] value can be passed directly
+// as the argument to WriteBuffers.
+type BuffersWriter interface {
+	// WriteBuffers writes all the data in bufs, returning the
+	// number of bytes written.
+	WriteBuffers(bufs [][]byte) (int64, error)
+}
+
+// WriteBuffers writes all of bufs to w. If w implements
+// [BuffersWriter], its WriteBuffers method is used; otherwise each
+// non-empty buffer is written with a separate call to Write.
+func WriteBuffers(w io.Writer, bufs [][]byte) (int64, error) {
+	if w, ok := w.(BuffersWriter); ok {
+		return w.WriteBuffers(bufs)
+	}
+	return writeBuffers(w, bufs)
+}
+
+func writeBuffers(w io.Writer, bufs [][]byte) (int64, error) {
+	tot := int64(0)
+	for _, buf := range bufs {
+		if len(buf) == 0 {
+			continue
+		}
+		n, err := w.Write(buf)
+		tot += int64(n)
+		if err != nil {
+			return tot, err
+		}
+	}
+	return tot, nil
+}
+
+// WriteBuffers implements [BuffersWriter] by yielding each
+// non-empty buffer as a separate element, without copying.
+func (w seqWriter) WriteBuffers(bufs [][]byte) (int64, error) {
+	return writeBuffers(w, bufs)
+}
+
+// WriteBuffers implements [BuffersWriter]. The buffers are written
+// as a group: no other write will be interleaved with them.
+func (w *syncSeqWriter) WriteBuffers(bufs [][]byte) (int64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return writeBuffers(w.w, bufs)
+}
+
+// WriteBuffers implements [BuffersWriter].
+func (b *BufferedSeqWriter) WriteBuffers(bufs [][]byte) (int64, error) {
+	return writeBuffers(b, bufs)
+}
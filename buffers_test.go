@@ -0,0 +1,42 @@
+package ioseq
+
+import (
+	"bytes"
+	"net"
+	"slices"
+	"testing"
+)
+
+func TestSeqWriterWriteBuffers(t *testing.T) {
+	header := []byte("header")
+	payload := []byte("payload")
+	var n int64
+	var writeErr error
+	seq := func(yield func([]byte, error) bool) {
+		n, writeErr = WriteBuffers(SeqWriter(yield, nil), net.Buffers{header, nil, payload})
+	}
+	got, err := collectStrings(seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if writeErr != nil {
+		t.Fatal(writeErr)
+	}
+	if want := []string{"header", "payload"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results; got %q want %q", got, want)
+	}
+	if n != 13 {
+		t.Errorf("unexpected count %d", n)
+	}
+}
+
+func TestWriteBuffersFallback(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := WriteBuffers(&buf, [][]byte{[]byte("a"), []byte("bc")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || buf.String() != "abc" {
+		t.Errorf("unexpected result %d, %q", n, buf.String())
+	}
+}
@@ -0,0 +1,159 @@
+package ioseq
+
+import (
+	"bytes"
+)
+
+// BufferedSeqReader is a reader that reads from a [Seq], providing
+// methods similar to those of [bufio.Reader]. Where possible, data is
+// returned directly from the chunks yielded by the sequence rather
+// than being copied into an intermediate buffer; data is only copied
+// when a request spans more than one chunk.
+//
+// As with [PullReader], a slice returned by any method remains valid
+// only until the next method call.
+type BufferedSeqReader struct {
+	r *PullReader
+
+	// buf[off:] holds data that has been taken from the sequence but
+	// not yet consumed. It always precedes any data in r.
+	buf []byte
+	off int
+}
+
+// NewBufferedSeqReader returns a new BufferedSeqReader that reads from
+// seq. The Close method must be called when the caller has finished
+// with it.
+func NewBufferedSeqReader(seq Seq) *BufferedSeqReader {
+	return &BufferedSeqReader{
+		r: NewPullReader(seq),
+	}
+}
+
+// current returns the next unconsumed data, which is always
+// non-empty unless there's an error.
+func (b *BufferedSeqReader) current() ([]byte, error) {
+	if b.off < len(b.buf) {
+		return b.buf[b.off:], nil
+	}
+	for {
+		data, err := b.r.Peek()
+		if err != nil || len(data) > 0 {
+			return data, err
+		}
+		// Skip empty chunks.
+		b.r.Next()
+	}
+}
+
+// consume consumes n bytes of the data returned by current.
+func (b *BufferedSeqReader) consume(n int) {
+	if b.off < len(b.buf) {
+		b.off += n
+		if b.off == len(b.buf) {
+			b.buf, b.off = b.buf[:0], 0
+		}
+		return
+	}
+	b.r.advance(n)
+}
+
+// Read implements [io.Reader].
+func (b *BufferedSeqReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	data, err := b.current()
+	if err != nil {
+		return 0, err
+	}
+	n := copy(p, data)
+	b.consume(n)
+	return n, nil
+}
+
+// ReadByte implements [io.ByteReader].
+func (b *BufferedSeqReader) ReadByte() (byte, error) {
+	data, err := b.current()
+	if err != nil {
+		return 0, err
+	}
+	c := data[0]
+	b.consume(1)
+	return c, nil
+}
+
+// Peek returns the next n bytes without consuming them. If fewer than
+// n bytes are available, it returns those bytes along with the error
+// that explains why, as [bufio.Reader.Peek] does. If the next n bytes
+// lie within a single chunk of the sequence, no copy is made.
+func (b *BufferedSeqReader) Peek(n int) ([]byte, error) {
+	if b.off == len(b.buf) {
+		data, err := b.current()
+		if err != nil {
+			return nil, err
+		}
+		if len(data) >= n {
+			return data[:n], nil
+		}
+	}
+	for len(b.buf)-b.off < n {
+		data, err := b.r.Next()
+		if err != nil {
+			return b.buf[b.off:], err
+		}
+		b.buf = append(b.buf, data...)
+	}
+	return b.buf[b.off : b.off+n], nil
+}
+
+// Discard skips the next n bytes, returning the number of bytes
+// discarded. If Discard skips fewer than n bytes, it also returns
+// an error.
+func (b *BufferedSeqReader) Discard(n int) (int, error) {
+	discarded := 0
+	for discarded < n {
+		data, err := b.current()
+		if err != nil {
+			return discarded, err
+		}
+		m := min(len(data), n-discarded)
+		b.consume(m)
+		discarded += m
+	}
+	return discarded, nil
+}
+
+// ReadString reads until the first occurrence of delim, returning a
+// string containing the data up to and including the delimiter. If
+// the sequence ends before the delimiter is found, it returns the data
+// read so far along with the error ([io.EOF] at the end of the
+// sequence).
+func (b *BufferedSeqReader) ReadString(delim byte) (string, error) {
+	var acc []byte
+	for {
+		data, err := b.current()
+		if err != nil {
+			return string(acc), err
+		}
+		if i := bytes.IndexByte(data, delim); i >= 0 {
+			var s string
+			if acc == nil {
+				// Avoid an extra copy in the common case.
+				s = string(data[:i+1])
+			} else {
+				s = string(append(acc, data[:i+1]...))
+			}
+			b.consume(i + 1)
+			return s, nil
+		}
+		acc = append(acc, data...)
+		b.consume(len(data))
+	}
+}
+
+// Close stops the underlying sequence.
+func (b *BufferedSeqReader) Close() error {
+	b.buf, b.off = nil, 0
+	return b.r.Close()
+}
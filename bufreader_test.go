@@ -0,0 +1,74 @@
+package ioseq
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestBufferedSeqReader(t *testing.T) {
+	r := NewBufferedSeqReader(seqOfStrings("GET /", "", "path HTTP/1.1\r\n", "Host: x\r", "\n\r\nbody"))
+	defer r.Close()
+
+	data, err := r.Peek(3)
+	if err != nil || string(data) != "GET" {
+		t.Fatalf("unexpected Peek result %q, %v", data, err)
+	}
+	// Peek across chunk boundaries.
+	data, err = r.Peek(9)
+	if err != nil || string(data) != "GET /path" {
+		t.Fatalf("unexpected Peek result %q, %v", data, err)
+	}
+	line, err := r.ReadString('\n')
+	if err != nil || line != "GET /path HTTP/1.1\r\n" {
+		t.Fatalf("unexpected ReadString result %q, %v", line, err)
+	}
+	c, err := r.ReadByte()
+	if err != nil || c != 'H' {
+		t.Fatalf("unexpected ReadByte result %q, %v", c, err)
+	}
+	n, err := r.Discard(5)
+	if err != nil || n != 5 {
+		t.Fatalf("unexpected Discard result %d, %v", n, err)
+	}
+	line, err = r.ReadString('\n')
+	if err != nil || line != "x\r\n" {
+		t.Fatalf("unexpected ReadString result %q, %v", line, err)
+	}
+	line, err = r.ReadString('\n')
+	if err != nil || line != "\r\n" {
+		t.Fatalf("unexpected ReadString result %q, %v", line, err)
+	}
+	data, err = r.Peek(10)
+	if err != io.EOF || string(data) != "body" {
+		t.Fatalf("unexpected Peek result %q, %v", data, err)
+	}
+	line, err = r.ReadString('\n')
+	if err != io.EOF || line != "body" {
+		t.Fatalf("unexpected ReadString result %q, %v", line, err)
+	}
+	if _, err := r.ReadByte(); err != io.EOF {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestBufferedSeqReaderRead(t *testing.T) {
+	r := NewBufferedSeqReader(seqOfStrings("hello", " ", "world"))
+	defer r.Close()
+	if _, err := r.Peek(7); err != nil {
+		t.Fatal(err)
+	}
+	if err := iotest.TestReader(r, []byte("hello world")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBufferedSeqReaderError(t *testing.T) {
+	r := NewBufferedSeqReader(ConcatSeqs(seqOfStrings("abc"), ErrorSeq(fmt.Errorf("some error"))))
+	defer r.Close()
+	n, err := r.Discard(5)
+	if n != 3 || err == nil || err.Error() != "some error" {
+		t.Fatalf("unexpected Discard result %d, %v", n, err)
+	}
+}
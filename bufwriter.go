@@ -0,0 +1,108 @@
+package ioseq
+
+// BufferedSeqWriter is like the writer returned by [SeqWriter] except
+// that it accumulates small writes into a buffer, yielding the buffered
+// data only when the buffer is full or [BufferedSeqWriter.Flush] is
+// called. This avoids producing many tiny chunks when the producer
+// makes many small writes, as encoders such as [encoding/base64] or
+// [fmt] do.
+//
+// Writes at least as large as the buffer are yielded directly, without
+// copying, when the buffer is empty.
+//
+// Flush or Close must be called after the last write, before the
+// iterator function returns; otherwise buffered data will be lost.
+type BufferedSeqWriter struct {
+	w   seqWriter
+	buf []byte
+	err error
+}
+
+// NewBufferedSeqWriter returns a [BufferedSeqWriter] with a buffer of
+// the given size that operates on the given yield function. The
+// active argument has the same meaning as for [SeqWriter].
+//
+// NewBufferedSeqWriter panics if size is not positive.
+func NewBufferedSeqWriter(yield func([]byte, error) bool, active *bool, size int) *BufferedSeqWriter {
+	if size <= 0 {
+		panic("ioseq: non-positive buffer size")
+	}
+	return &BufferedSeqWriter{
+		w:   SeqWriter(yield, active).(seqWriter),
+		buf: make([]byte, 0, size),
+	}
+}
+
+// Available returns how many bytes are unused in the buffer.
+func (b *BufferedSeqWriter) Available() int {
+	return cap(b.buf) - len(b.buf)
+}
+
+// Buffered returns the number of bytes that have been written into
+// the buffer but not yet yielded.
+func (b *BufferedSeqWriter) Buffered() int {
+	return len(b.buf)
+}
+
+// Write implements [io.Writer]. Once a write has failed, all
+// subsequent writes return the same error.
+func (b *BufferedSeqWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > b.Available() && b.err == nil {
+		var m int
+		if len(b.buf) == 0 {
+			// Large write, empty buffer: yield it
+			// directly to avoid copying.
+			m, b.err = b.w.Write(p)
+		} else {
+			m = copy(b.buf[len(b.buf):cap(b.buf)], p)
+			b.buf = b.buf[:len(b.buf)+m]
+			b.Flush()
+		}
+		n += m
+		p = p[m:]
+	}
+	if b.err != nil {
+		return n, b.err
+	}
+	b.buf = append(b.buf, p...)
+	return n + len(p), nil
+}
+
+// WriteString implements [io.StringWriter].
+func (b *BufferedSeqWriter) WriteString(s string) (int, error) {
+	return b.Write(stringBytes(s))
+}
+
+// Flush yields any buffered data.
+func (b *BufferedSeqWriter) Flush() error {
+	if b.err != nil {
+		return b.err
+	}
+	if len(b.buf) == 0 {
+		return nil
+	}
+	if _, err := b.w.Write(b.buf); err != nil {
+		b.err = err
+		return err
+	}
+	b.buf = b.buf[:0]
+	return nil
+}
+
+// Close flushes any buffered data and ends the sequence.
+// It implements [SeqWriteCloser.Close].
+func (b *BufferedSeqWriter) Close() error {
+	return b.CloseWithError(nil)
+}
+
+// CloseWithError flushes any buffered data and then ends the sequence,
+// yielding err if it is not nil. It implements
+// [SeqWriteCloser.CloseWithError].
+func (b *BufferedSeqWriter) CloseWithError(err error) error {
+	if ferr := b.Flush(); ferr != nil {
+		return ferr
+	}
+	b.err = ErrSequenceTerminated
+	return b.w.CloseWithError(err)
+}
@@ -0,0 +1,99 @@
+package ioseq
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBufferedSeqWriter(t *testing.T) {
+	seq := func(yield func([]byte, error) bool) {
+		w := NewBufferedSeqWriter(yield, nil, 8)
+		for i := range 5 {
+			fmt.Fprint(w, i)
+		}
+		io.WriteString(w, "abcdef")
+		w.Flush()
+		// A large write when the buffer is empty is yielded directly.
+		w.Write([]byte("0123456789"))
+		io.WriteString(w, "x")
+		w.Flush()
+	}
+	got, err := collectStrings(seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"01234abc", "def", "0123456789", "x"}
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestBufferedSeqWriterTerminated(t *testing.T) {
+	var writeErr, flushErr error
+	seq := func(yield func([]byte, error) bool) {
+		w := NewBufferedSeqWriter(yield, nil, 4)
+		for writeErr == nil {
+			_, writeErr = io.WriteString(w, "ab")
+		}
+		flushErr = w.Flush()
+	}
+	for data := range seq {
+		if got, want := string(data), "abab"; got != want {
+			t.Errorf("unexpected data; got %q want %q", got, want)
+		}
+		break
+	}
+	if writeErr != ErrSequenceTerminated {
+		t.Errorf("unexpected write error; got %v want %v", writeErr, ErrSequenceTerminated)
+	}
+	if flushErr != ErrSequenceTerminated {
+		t.Errorf("unexpected flush error; got %v want %v", flushErr, ErrSequenceTerminated)
+	}
+}
+
+func TestBufferedSeqWriterMatchesInput(t *testing.T) {
+	var sb strings.Builder
+	for i := range 1000 {
+		fmt.Fprintf(&sb, "%d,", i)
+	}
+	want := sb.String()
+	seq := func(yield func([]byte, error) bool) {
+		w := NewBufferedSeqWriter(yield, nil, 100)
+		for i := range 1000 {
+			fmt.Fprintf(w, "%d,", i)
+		}
+		w.Flush()
+	}
+	got, err := collectStrings(seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range got[:len(got)-1] {
+		if len(s) != 100 {
+			t.Fatalf("unexpected chunk size %d", len(s))
+		}
+	}
+	if strings.Join(got, "") != want {
+		t.Errorf("unexpected content")
+	}
+}
+
+func TestBufferedSeqWriterCloseWithError(t *testing.T) {
+	seqErr := errors.New("some error")
+	seq := func(yield func([]byte, error) bool) {
+		w := NewBufferedSeqWriter(yield, nil, 10)
+		io.WriteString(w, "hello")
+		w.CloseWithError(seqErr)
+	}
+	got, err := collectStrings(seq)
+	if err != seqErr {
+		t.Fatalf("unexpected error; got %v want %v", err, seqErr)
+	}
+	if want := []string{"hello"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results; got %q want %q", got, want)
+	}
+}
@@ -0,0 +1,88 @@
+package ioseq
+
+import (
+	"bytes"
+	"sync"
+)
+
+// SeqFromChan returns a [Seq] that yields each slice received from c
+// until c is closed. If errc is non-nil, a value is then received from
+// it, and yielded if it's a non-nil error. The slices sent on c become
+// owned by the sequence.
+//
+// If the consumer stops iterating early, no more values are received
+// from c; it's up to the caller to make sure that the sender does not
+// block forever in that case. The channels returned by [ChanFromSeq]
+// can be used directly, calling its stop function when done.
+func SeqFromChan(c <-chan []byte, errc <-chan error) Seq {
+	return func(yield func([]byte, error) bool) {
+		for data := range c {
+			if !yield(data, nil) {
+				return
+			}
+		}
+		if errc == nil {
+			return
+		}
+		if err := <-errc; err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// ChanFromSeq starts a goroutine that iterates over seq, sending a copy
+// of each chunk on the returned data channel, which has a buffer of
+// bufSize elements. When seq finishes, the data channel is closed and
+// then the error that terminated seq, if any, is sent on errc, which
+// is then closed too.
+//
+// Calling stop causes the goroutine to stop iterating over seq and
+// close both channels without sending an error. It waits for the
+// goroutine to finish, and may be called more than once. The caller
+// must call stop unless it has received from c until it was closed.
+func ChanFromSeq(seq Seq, bufSize int) (c <-chan []byte, errc <-chan error, stop func()) {
+	datac := make(chan []byte, bufSize)
+	errc1 := make(chan error, 1)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		defer close(errc1)
+		for data, err := range seq {
+			if err != nil {
+				close(datac)
+				errc1 <- err
+				return
+			}
+			select {
+			case datac <- bytes.Clone(data):
+			case <-done:
+				close(datac)
+				return
+			}
+		}
+		close(datac)
+	}()
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			close(done)
+		})
+		<-finished
+	}
+	return datac, errc1, stop
+}
+
+// AsyncSeq returns a [Seq] that iterates over seq in a separate
+// goroutine, buffering copies of up to n chunks ahead of the consumer.
+// This decouples a slow or bursty producer from the consumer.
+//
+// If the consumer stops iterating early, the producer is stopped too:
+// the iteration does not finish until the producer has returned.
+func AsyncSeq(seq Seq, n int) Seq {
+	return func(yield func([]byte, error) bool) {
+		c, errc, stop := ChanFromSeq(seq, n)
+		defer stop()
+		SeqFromChan(c, errc)(yield)
+	}
+}
@@ -0,0 +1,90 @@
+package ioseq
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSeqFromChan(t *testing.T) {
+	c := make(chan []byte, 2)
+	errc := make(chan error, 1)
+	c <- []byte("foo")
+	c <- []byte("bar")
+	close(c)
+	errc <- fmt.Errorf("some error")
+	got, err := collectStrings(SeqFromChan(c, errc))
+	if want := []string{"foo", "bar"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestChanFromSeqRoundTrip(t *testing.T) {
+	in := ConcatSeqs(reusingSeq("foo", "bar", "baz"), ErrorSeq(fmt.Errorf("some error")))
+	c, errc, stop := ChanFromSeq(in, 1)
+	defer stop()
+	got, err := collectStrings(SeqFromChan(c, errc))
+	if want := []string{"foo", "bar", "baz"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestChanFromSeqStop(t *testing.T) {
+	stopped := false
+	in := func(yield func([]byte, error) bool) {
+		defer func() {
+			stopped = true
+		}()
+		for {
+			if !yield([]byte("x"), nil) {
+				return
+			}
+		}
+	}
+	c, errc, stop := ChanFromSeq(in, 0)
+	for range SeqFromChan(c, errc) {
+		break
+	}
+	stop()
+	if !stopped {
+		t.Errorf("sequence was not stopped")
+	}
+	if err := <-errc; err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestAsyncSeq(t *testing.T) {
+	seqErr := errors.New("some error")
+	got, err := collectStrings(AsyncSeq(ConcatSeqs(seqOfStrings("one", "two", "three"), ErrorSeq(seqErr)), 2))
+	if want := []string{"one", "two", "three"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results; got %q want %q", got, want)
+	}
+	if err != seqErr {
+		t.Errorf("unexpected error; got %v want %v", err, seqErr)
+	}
+}
+
+func TestAsyncSeqEarlyStop(t *testing.T) {
+	stopped := false
+	seq := func(yield func([]byte, error) bool) {
+		defer func() {
+			stopped = true
+		}()
+		for yield([]byte("data"), nil) {
+		}
+	}
+	for range AsyncSeq(seq, 3) {
+		break
+	}
+	if !stopped {
+		t.Errorf("producer not stopped")
+	}
+}
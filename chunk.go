@@ -0,0 +1,90 @@
+package ioseq
+
+// RechunkSeq returns a [Seq] that yields the same data as seq, but in
+// chunks of exactly size bytes, except for the final chunk, which may
+// be shorter.
+//
+// Where a whole output chunk lies within a single chunk of seq, it is
+// yielded without copying; otherwise data is accumulated in a buffer
+// of the given size.
+//
+// RechunkSeq panics if size is not positive.
+func RechunkSeq(seq Seq, size int) Seq {
+	if size <= 0 {
+		panic("ioseq: non-positive chunk size")
+	}
+	return func(yield func([]byte, error) bool) {
+		var buf []byte
+		for data, err := range seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if len(buf) > 0 {
+				n := copy(buf[len(buf):size], data)
+				buf = buf[:len(buf)+n]
+				data = data[n:]
+				if len(buf) < size {
+					continue
+				}
+				if !yield(buf, nil) {
+					return
+				}
+				buf = buf[:0]
+			}
+			for len(data) >= size {
+				if !yield(data[:size:size], nil) {
+					return
+				}
+				data = data[size:]
+			}
+			if len(data) > 0 {
+				if buf == nil {
+					buf = make([]byte, 0, size)
+				}
+				buf = append(buf, data...)
+			}
+		}
+		if len(buf) > 0 {
+			yield(buf, nil)
+		}
+	}
+}
+
+// WindowSeq returns a [Seq] that yields each non-empty chunk of seq
+// with up to overlap bytes of the preceding data prepended, so that
+// a pattern of up to overlap+1 bytes that straddles a chunk boundary
+// in seq will appear entirely within one yielded chunk.
+//
+// The number of bytes prepended to a chunk is the smaller of overlap
+// and the total number of bytes in seq before that chunk, which
+// allows the consumer to avoid reporting a match twice.
+//
+// Unlike most sequences in this package, the data yielded by the
+// returned sequence is not the same as the data in seq: the
+// overlapping bytes appear more than once.
+func WindowSeq(seq Seq, overlap int) Seq {
+	if overlap <= 0 {
+		return seq
+	}
+	return func(yield func([]byte, error) bool) {
+		var buf []byte
+		prefix := 0
+		for data, err := range seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if len(data) == 0 {
+				continue
+			}
+			buf = append(buf[:prefix], data...)
+			if !yield(buf, nil) {
+				return
+			}
+			// Keep the trailing bytes for the next chunk.
+			prefix = min(overlap, len(buf))
+			copy(buf, buf[len(buf)-prefix:])
+		}
+	}
+}
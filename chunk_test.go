@@ -0,0 +1,61 @@
+package ioseq
+
+import (
+	"slices"
+	"testing"
+)
+
+var rechunkSeqTests = []struct {
+	testName string
+	in       []string
+	size     int
+	want     []string
+}{{
+	testName: "Empty",
+	size:     3,
+	want:     nil,
+}, {
+	testName: "Exact",
+	in:       []string{"abcdef"},
+	size:     3,
+	want:     []string{"abc", "def"},
+}, {
+	testName: "ShortFinal",
+	in:       []string{"abcdefg"},
+	size:     3,
+	want:     []string{"abc", "def", "g"},
+}, {
+	testName: "Coalesce",
+	in:       []string{"a", "b", "", "cd", "e", "fghij"},
+	size:     3,
+	want:     []string{"abc", "def", "ghi", "j"},
+}, {
+	testName: "Mixed",
+	in:       []string{"ab", "cdefghi", "jk"},
+	size:     4,
+	want:     []string{"abcd", "efgh", "ijk"},
+}}
+
+func TestRechunkSeq(t *testing.T) {
+	for _, test := range rechunkSeqTests {
+		t.Run(test.testName, func(t *testing.T) {
+			got, err := collectStrings(RechunkSeq(seqOfStrings(test.in...), test.size))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("unexpected results;\ngot %q\nwant %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWindowSeq(t *testing.T) {
+	got, err := collectStrings(WindowSeq(seqOfStrings("abc", "", "d", "efgh"), 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"abc", "bcd", "cdefgh"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+}
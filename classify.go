@@ -0,0 +1,89 @@
+package ioseq
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+// ErrorClass describes how an error should be handled.
+// See [ErrorClassifier].
+type ErrorClass int
+
+const (
+	// ErrorFatal indicates an error that should be reported
+	// and not retried.
+	ErrorFatal ErrorClass = iota
+
+	// ErrorRetryable indicates a transient error after which
+	// the operation may succeed if retried.
+	ErrorRetryable
+
+	// ErrorStop indicates that the consumer stopped on purpose,
+	// for example by terminating the sequence or cancelling a
+	// context. It should not be retried, and need not be
+	// treated as a failure.
+	ErrorStop
+)
+
+// ErrorClassifier is implemented by types that can classify errors.
+// It is used by [RetryPolicy] and [Pipeline] so that the decisions
+// about which errors to retry and which to report can be tailored to
+// a particular backend.
+type ErrorClassifier interface {
+	ClassifyError(err error) ErrorClass
+}
+
+// ErrorClassifierFunc implements [ErrorClassifier]
+// by calling the function.
+type ErrorClassifierFunc func(err error) ErrorClass
+
+// ClassifyError implements [ErrorClassifier.ClassifyError].
+func (f ErrorClassifierFunc) ClassifyError(err error) ErrorClass {
+	return f(err)
+}
+
+// DefaultErrorClassifier is an [ErrorClassifier] with defaults that
+// suit network transfers:
+//
+//   - [ErrSequenceTerminated], [context.Canceled] and [ErrClosed]
+//     are classified as [ErrorStop].
+//   - Errors from timeouts (including [ErrStalled],
+//     [context.DeadlineExceeded] and [net.Error] values reporting a
+//     timeout), connections being reset, refused or broken, and
+//     [io.ErrUnexpectedEOF] are classified as [ErrorRetryable].
+//   - Errors with a StatusCode method reporting a 5xx HTTP status
+//     or 429 (Too Many Requests), such as the StatusError type in
+//     the httpseq package, are classified as [ErrorRetryable].
+//   - All other errors are classified as [ErrorFatal].
+var DefaultErrorClassifier ErrorClassifier = ErrorClassifierFunc(defaultClassifyError)
+
+func defaultClassifyError(err error) ErrorClass {
+	switch {
+	case errors.Is(err, ErrSequenceTerminated),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, ErrClosed):
+		return ErrorStop
+	case errors.Is(err, ErrStalled),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNABORTED),
+		errors.Is(err, syscall.EPIPE):
+		return ErrorRetryable
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorRetryable
+	}
+	var statusErr interface{ StatusCode() int }
+	if errors.As(err, &statusErr) {
+		if code := statusErr.StatusCode(); code >= 500 || code == 429 {
+			return ErrorRetryable
+		}
+	}
+	return ErrorFatal
+}
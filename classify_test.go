@@ -0,0 +1,123 @@
+package ioseq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"syscall"
+	"testing"
+)
+
+type statusError int
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("status %d", int(e))
+}
+
+func (e statusError) StatusCode() int {
+	return int(e)
+}
+
+var defaultClassifierTests = []struct {
+	err  error
+	want ErrorClass
+}{
+	{ErrSequenceTerminated, ErrorStop},
+	{&TerminatedError{Cause: errors.New("x")}, ErrorStop},
+	{fmt.Errorf("wrapped: %w", context.Canceled), ErrorStop},
+	{ErrClosed, ErrorStop},
+	{ErrStalled, ErrorRetryable},
+	{context.DeadlineExceeded, ErrorRetryable},
+	{io.ErrUnexpectedEOF, ErrorRetryable},
+	{&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, ErrorRetryable},
+	{&net.DNSError{IsTimeout: true}, ErrorRetryable},
+	{statusError(503), ErrorRetryable},
+	{statusError(429), ErrorRetryable},
+	{statusError(404), ErrorFatal},
+	{errors.New("other"), ErrorFatal},
+	{&StageError{Stage: "x", Err: ErrStalled}, ErrorRetryable},
+}
+
+func TestDefaultErrorClassifier(t *testing.T) {
+	for _, test := range defaultClassifierTests {
+		if got := DefaultErrorClassifier.ClassifyError(test.err); got != test.want {
+			t.Errorf("unexpected class for %v; got %d want %d", test.err, got, test.want)
+		}
+	}
+}
+
+func TestRetryPolicyClassifier(t *testing.T) {
+	fatal := errors.New("fatal")
+	calls := 0
+	seq := RetrySeq(func(offset int64) (io.ReadCloser, error) {
+		calls++
+		if calls == 1 {
+			return nil, statusError(502)
+		}
+		return nil, fatal
+	}, RetryPolicy{
+		MaxRetries: 5,
+		Classifier: DefaultErrorClassifier,
+	})
+	if _, err := ReadAllSeq(seq); err != fatal {
+		t.Errorf("unexpected error %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("unexpected call count %d", calls)
+	}
+}
+
+func TestPipelineWithErrorClassifier(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p := NewPipeline(seqOfStrings("a"))
+	sink := func(ctx context.Context, seq Seq) error {
+		_, err := DiscardSeq(seq)
+		return err
+	}
+	if err := p.Run(ctx, sink); !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := p.WithErrorClassifier(DefaultErrorClassifier).Run(ctx, sink); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestPipelineWithErrorClassifierStage(t *testing.T) {
+	var got []string
+	err := NewPipeline(seqOfStrings("a", "b")).
+		WithErrorClassifier(DefaultErrorClassifier).
+		Then("stop", func(ctx context.Context, in Seq) Seq {
+			return ConcatSeqs(in, ErrorSeq(ErrSequenceTerminated), SeqFromString("c"))
+		}).
+		Then("record", func(ctx context.Context, in Seq) Seq {
+			return func(yield func([]byte, error) bool) {
+				for data, err := range in {
+					if err != nil {
+						got = append(got, "error "+err.Error())
+						yield(nil, err)
+						return
+					}
+					got = append(got, string(data))
+					if !yield(data, nil) {
+						return
+					}
+				}
+				got = append(got, "end")
+			}
+		}).
+		Run(context.Background(), func(ctx context.Context, seq Seq) error {
+			_, err := DiscardSeq(seq)
+			return err
+		})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := []string{"a", "b", "end"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results; got %q want %q", got, want)
+	}
+}
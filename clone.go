@@ -0,0 +1,75 @@
+package ioseq
+
+import (
+	"bytes"
+	"sync"
+)
+
+// CloneSeq returns a [Seq] that yields a copy of each chunk of seq.
+// Unlike with most sequences, the consumer owns the yielded slices
+// and may retain or modify them.
+func CloneSeq(seq Seq) Seq {
+	return func(yield func([]byte, error) bool) {
+		for data, err := range seq {
+			if !yield(bytes.Clone(data), err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// CloneSeqPool is like [CloneSeq] except that the copies are made into
+// buffers obtained from pool. The consumer owns the yielded slices and
+// may retain them; when it has finished with a slice, it can return it
+// to the pool with [ChunkPool.Put] so that it can be reused.
+func CloneSeqPool(seq Seq, pool *ChunkPool) Seq {
+	return func(yield func([]byte, error) bool) {
+		for data, err := range seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			buf := pool.Get(len(data))
+			copy(buf, data)
+			if !yield(buf, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ChunkPool is a pool of byte slices. The zero value
+// is ready to use.
+type ChunkPool struct {
+	pool sync.Pool
+}
+
+// Get returns a slice of length n, reusing a slice
+// from the pool if there's one with sufficient capacity.
+func (p *ChunkPool) Get(n int) []byte {
+	return *p.get(n)
+}
+
+// Put returns buf to the pool. The caller must not use
+// buf after calling Put.
+func (p *ChunkPool) Put(buf []byte) {
+	if cap(buf) == 0 {
+		return
+	}
+	p.put(&buf)
+}
+
+// get is like Get but returns a pointer to the slice so
+// that it can be returned to the pool without allocating.
+func (p *ChunkPool) get(n int) *[]byte {
+	if buf, ok := p.pool.Get().(*[]byte); ok && cap(*buf) >= n {
+		*buf = (*buf)[:n]
+		return buf
+	}
+	buf := make([]byte, n)
+	return &buf
+}
+
+func (p *ChunkPool) put(buf *[]byte) {
+	p.pool.Put(buf)
+}
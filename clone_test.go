@@ -0,0 +1,59 @@
+package ioseq
+
+import (
+	"slices"
+	"testing"
+)
+
+// reusingSeq returns a Seq that yields each of the given strings
+// in turn, reusing the same buffer for each one.
+func reusingSeq(ss ...string) Seq {
+	return func(yield func([]byte, error) bool) {
+		var buf []byte
+		for _, s := range ss {
+			buf = append(buf[:0], s...)
+			if !yield(buf, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestCloneSeq(t *testing.T) {
+	var retained [][]byte
+	for data, err := range CloneSeq(reusingSeq("foo", "bar", "baz")) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		retained = append(retained, data)
+	}
+	var got []string
+	for _, data := range retained {
+		got = append(got, string(data))
+	}
+	if want := []string{"foo", "bar", "baz"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestCloneSeqPool(t *testing.T) {
+	var pool ChunkPool
+	var retained [][]byte
+	for data, err := range CloneSeqPool(reusingSeq("foo", "bar", "bazzz"), &pool) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		retained = append(retained, data)
+	}
+	var got []string
+	for _, data := range retained {
+		got = append(got, string(data))
+		pool.Put(data)
+	}
+	if want := []string{"foo", "bar", "bazzz"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if buf := pool.Get(2); len(buf) != 2 {
+		t.Errorf("unexpected length %d from pool", len(buf))
+	}
+}
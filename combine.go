@@ -0,0 +1,194 @@
+package ioseq
+
+import (
+	"bytes"
+	"iter"
+	"sync"
+)
+
+// ConcatSeqs returns a [Seq] that yields all the data from each of the
+// given sequences in turn. It is the Seq analogue of [io.MultiReader].
+// Iteration stops at the first error.
+func ConcatSeqs(seqs ...Seq) Seq {
+	seqs = append([]Seq(nil), seqs...)
+	return func(yield func([]byte, error) bool) {
+		for _, seq := range seqs {
+			for data, err := range seq {
+				if !yield(data, err) || err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// InterleaveSeqs returns a [Seq] that yields chunks from each of the
+// given sequences in round-robin order: the first chunk of each
+// sequence, then the second chunk of each, and so on. Sequences that
+// finish drop out of the rotation. Iteration stops at the first error
+// from any of the sequences.
+func InterleaveSeqs(seqs ...Seq) Seq {
+	seqs = append([]Seq(nil), seqs...)
+	return func(yield func([]byte, error) bool) {
+		next := 0
+		roundRobin := func(active []bool) int {
+			for !active[next%len(active)] {
+				next++
+			}
+			i := next % len(active)
+			next++
+			return i
+		}
+		InterleaveSeqsFunc(roundRobin, seqs...)(yield)
+	}
+}
+
+// InterleaveSeqsFunc is like [InterleaveSeqs] except that the order of
+// the chunks is determined by calling choose before each chunk. The
+// active slice indicates which of the sequences have not yet finished
+// (there is always at least one); choose should return the index of an
+// active sequence to take the next chunk from. choose must not retain
+// the slice.
+//
+// InterleaveSeqsFunc panics if choose returns the index of a sequence
+// that is not active.
+func InterleaveSeqsFunc(choose func(active []bool) int, seqs ...Seq) Seq {
+	seqs = append([]Seq(nil), seqs...)
+	return func(yield func([]byte, error) bool) {
+		nexts := make([]func() ([]byte, error, bool), len(seqs))
+		for i, seq := range seqs {
+			next, stop := iter.Pull2(seq)
+			defer stop()
+			nexts[i] = next
+		}
+		active := make([]bool, len(seqs))
+		nactive := len(seqs)
+		for i := range active {
+			active[i] = true
+		}
+		for nactive > 0 {
+			i := choose(active)
+			if i < 0 || i >= len(active) || !active[i] {
+				panic("ioseq: interleave chose an inactive sequence")
+			}
+			data, err, ok := nexts[i]()
+			if !ok {
+				active[i] = false
+				nactive--
+				continue
+			}
+			if !yield(data, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// BroadcastSeq returns n sequences that each yield all the data from
+// seq. The returned sequences are intended to be consumed concurrently
+// in separate goroutines; seq itself is iterated over in a new
+// goroutine started when the first of them is iterated over.
+//
+// Each chunk from seq is copied once and shared between all the
+// returned sequences, so, as usual, consumers must not modify it.
+// Up to bufSize chunks are buffered for each consumer; when a
+// consumer's buffer is full, the producer waits for it, so the
+// whole broadcast proceeds at the rate of the slowest consumer.
+//
+// A consumer that stops iterating early drops out of the broadcast
+// without holding up the others; when all consumers have stopped,
+// iteration over seq stops too. Every returned sequence must be
+// iterated over at most once, and each one must be iterated over
+// at some point (even if only to break out of the loop immediately),
+// otherwise the producer will eventually block forever.
+func BroadcastSeq(seq Seq, n, bufSize int) []Seq {
+	return fanOut(seq, n, bufSize, false)
+}
+
+// ShardSeq returns n sequences that between them yield all the data
+// from seq: the chunks of seq are distributed round-robin, so the
+// first chunk goes to the first sequence, the second chunk to the
+// second, and so on, with the (n+1)th chunk going to the first
+// sequence again. Each returned sequence yields its chunks in their
+// original order. To distribute fixed-size records rather than
+// arbitrary chunks, use [RechunkSeq] on seq first.
+//
+// Any error from seq is yielded by all the returned sequences.
+//
+// The returned sequences are intended to be consumed concurrently,
+// and the same rules about buffering, copying and early termination
+// apply as for [BroadcastSeq]. Chunks destined for a consumer that has
+// stopped early are discarded.
+func ShardSeq(seq Seq, n, bufSize int) []Seq {
+	return fanOut(seq, n, bufSize, true)
+}
+
+// fanOut implements BroadcastSeq and ShardSeq. If shard is true, each
+// chunk is sent to one consumer in turn; otherwise it's sent to all
+// of them.
+func fanOut(seq Seq, n, bufSize int, shard bool) []Seq {
+	type item struct {
+		data []byte
+		err  error
+	}
+	chans := make([]chan item, n)
+	dones := make([]chan struct{}, n)
+	for i := range chans {
+		chans[i] = make(chan item, bufSize)
+		dones[i] = make(chan struct{})
+	}
+	produce := func() {
+		defer func() {
+			for _, c := range chans {
+				close(c)
+			}
+		}()
+		live := make([]bool, n)
+		for i := range live {
+			live[i] = true
+		}
+		nlive := n
+		send := func(i int, it item) {
+			if !live[i] {
+				return
+			}
+			select {
+			case chans[i] <- it:
+			case <-dones[i]:
+				live[i] = false
+				nlive--
+			}
+		}
+		chunk := 0
+		for data, err := range seq {
+			it := item{bytes.Clone(data), err}
+			if shard && err == nil {
+				send(chunk%n, it)
+				chunk++
+			} else {
+				for i := range chans {
+					send(i, it)
+				}
+			}
+			if nlive == 0 {
+				return
+			}
+		}
+	}
+	var start sync.Once
+	seqs := make([]Seq, n)
+	for i := range seqs {
+		seqs[i] = func(yield func([]byte, error) bool) {
+			start.Do(func() {
+				go produce()
+			})
+			defer close(dones[i])
+			for it := range chans[i] {
+				if !yield(it.data, it.err) {
+					return
+				}
+			}
+		}
+	}
+	return seqs
+}
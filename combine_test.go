@@ -0,0 +1,219 @@
+package ioseq
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestConcatSeqs(t *testing.T) {
+	got, err := collectStrings(ConcatSeqs(
+		seqOfStrings("a", "b"),
+		seqOfStrings(),
+		seqOfStrings("c"),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestConcatSeqsStopsAtError(t *testing.T) {
+	failing := func(yield func([]byte, error) bool) {
+		if !yield([]byte("b"), nil) {
+			return
+		}
+		yield(nil, fmt.Errorf("some error"))
+	}
+	got, err := collectStrings(ConcatSeqs(
+		seqOfStrings("a"),
+		failing,
+		seqOfStrings("c"),
+	))
+	if want := []string{"a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestBroadcastSeq(t *testing.T) {
+	var in []string
+	for i := range 100 {
+		in = append(in, fmt.Sprint(i))
+	}
+	seqs := BroadcastSeq(seqOfStrings(in...), 3, 2)
+	results := make([][]string, len(seqs))
+	var wg sync.WaitGroup
+	for i, seq := range seqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := collectStrings(seq)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = got
+		}()
+	}
+	wg.Wait()
+	for i, got := range results {
+		if !slices.Equal(got, in) {
+			t.Errorf("consumer %d: unexpected results;\ngot %q\nwant %q", i, got, in)
+		}
+	}
+}
+
+func TestBroadcastSeqEarlyStop(t *testing.T) {
+	producerDone := make(chan struct{})
+	in := func(yield func([]byte, error) bool) {
+		defer close(producerDone)
+		for {
+			if !yield([]byte("x"), nil) {
+				return
+			}
+		}
+	}
+	seqs := BroadcastSeq(in, 2, 1)
+	var wg sync.WaitGroup
+	for i, seq := range seqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := 0
+			for range seq {
+				n++
+				if n > i*10 {
+					break
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	// The producer should notice that all consumers have gone away.
+	<-producerDone
+}
+
+func TestBroadcastSeqError(t *testing.T) {
+	in := func(yield func([]byte, error) bool) {
+		if !yield([]byte("a"), nil) {
+			return
+		}
+		yield(nil, fmt.Errorf("some error"))
+	}
+	seqs := BroadcastSeq(in, 2, 0)
+	errs := make([]error, len(seqs))
+	var wg sync.WaitGroup
+	for i, seq := range seqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = collectStrings(seq)
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err == nil || err.Error() != "some error" {
+			t.Errorf("consumer %d: unexpected error %v", i, err)
+		}
+	}
+}
+
+func TestInterleaveSeqs(t *testing.T) {
+	got, err := collectStrings(InterleaveSeqs(
+		seqOfStrings("a1", "a2", "a3"),
+		seqOfStrings(),
+		seqOfStrings("b1"),
+		seqOfStrings("c1", "c2"),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a1", "b1", "c1", "a2", "c2", "a3"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestInterleaveSeqsFunc(t *testing.T) {
+	// Always choose the last active sequence.
+	last := func(active []bool) int {
+		for i := len(active) - 1; ; i-- {
+			if active[i] {
+				return i
+			}
+		}
+	}
+	got, err := collectStrings(InterleaveSeqsFunc(last,
+		seqOfStrings("a1", "a2"),
+		seqOfStrings("b1", "b2"),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"b1", "b2", "a1", "a2"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestInterleaveSeqsError(t *testing.T) {
+	stopped := false
+	endless := func(yield func([]byte, error) bool) {
+		defer func() {
+			stopped = true
+		}()
+		for {
+			if !yield([]byte("x"), nil) {
+				return
+			}
+		}
+	}
+	failing := func(yield func([]byte, error) bool) {
+		yield(nil, fmt.Errorf("some error"))
+	}
+	got, err := collectStrings(InterleaveSeqs(endless, failing))
+	if want := []string{"x"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if !stopped {
+		t.Errorf("other sequence was not stopped")
+	}
+}
+
+func TestShardSeq(t *testing.T) {
+	var in []string
+	for i := range 10 {
+		in = append(in, fmt.Sprint(i))
+	}
+	seqs := ShardSeq(seqOfStrings(in...), 3, 1)
+	results := make([][]string, len(seqs))
+	var wg sync.WaitGroup
+	for i, seq := range seqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := collectStrings(seq)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = got
+		}()
+	}
+	wg.Wait()
+	want := [][]string{
+		{"0", "3", "6", "9"},
+		{"1", "4", "7"},
+		{"2", "5", "8"},
+	}
+	for i, got := range results {
+		if !slices.Equal(got, want[i]) {
+			t.Errorf("shard %d: unexpected results;\ngot %q\nwant %q", i, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package ioseq
+
+import (
+	"io"
+	"slices"
+)
+
+// WriterToSeq returns a [io.WriteCloser] that makes the data written
+// to it available as a [Seq] to consume, which is called in a separate
+// goroutine. This is the inverse of [ReaderWithContent]: it makes it
+// possible to pass a Seq-based consumer to an API that writes to an
+// [io.Writer].
+//
+// Each Write yields its data as a single element and blocks until
+// the consumer has finished with it, so no copying is needed. If the
+// consumer stops iterating, subsequent writes fail with
+// [ErrSequenceTerminated]; if consume returns a non-nil error, writes
+// made after it has returned fail with that error.
+//
+// Close ends the sequence, waits for consume to return, and returns
+// its error. Close must be called even if consume has already
+// returned.
+func WriterToSeq(consume func(Seq) error) io.WriteCloser {
+	w := &consumerWriter{
+		data: make(chan []byte),
+		acks: make(chan bool),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(w.done)
+		w.err = consume(w.seq)
+	}()
+	return w
+}
+
+type consumerWriter struct {
+	// data receives each chunk written to the writer.
+	data chan []byte
+	// acks receives a value when the consumer has finished
+	// with a chunk, reporting whether it wants another one.
+	acks chan bool
+	// done is closed when consume has returned.
+	done chan struct{}
+	// err holds the error returned by consume.
+	// It is only valid after done has been closed.
+	err error
+	// stopped is set when the consumer has stopped iterating.
+	stopped bool
+	closed  bool
+}
+
+func (w *consumerWriter) seq(yield func([]byte, error) bool) {
+	for data := range w.data {
+		ok := yield(data, nil)
+		w.acks <- ok
+		if !ok {
+			return
+		}
+	}
+}
+
+func (w *consumerWriter) Write(buf []byte) (int, error) {
+	switch {
+	case w.closed:
+		return 0, io.ErrClosedPipe
+	case w.stopped:
+		return 0, ErrSequenceTerminated
+	case len(buf) == 0:
+		return 0, nil
+	}
+	select {
+	case w.data <- slices.Clip(buf):
+	case <-w.done:
+		if w.err != nil {
+			return 0, w.err
+		}
+		return 0, ErrSequenceTerminated
+	}
+	if !<-w.acks {
+		w.stopped = true
+	}
+	return len(buf), nil
+}
+
+func (w *consumerWriter) Close() error {
+	if !w.closed {
+		w.closed = true
+		close(w.data)
+	}
+	<-w.done
+	return w.err
+}
@@ -0,0 +1,59 @@
+package ioseq
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestWriterToSeq(t *testing.T) {
+	var got []string
+	w := WriterToSeq(func(seq Seq) error {
+		var err error
+		got, err = collectStrings(seq)
+		return err
+	})
+	for _, s := range []string{"one", "", "two", "three"} {
+		if _, err := fmt.Fprint(w, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"one", "two", "three"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestWriterToSeqConsumerStops(t *testing.T) {
+	w := WriterToSeq(func(seq Seq) error {
+		for range seq {
+			break
+		}
+		return nil
+	})
+	if _, err := w.Write([]byte("one")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("two")); err != ErrSequenceTerminated {
+		t.Fatalf("unexpected error from Write; got %v want %v", err, ErrSequenceTerminated)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriterToSeqConsumerError(t *testing.T) {
+	consumeErr := errors.New("consume error")
+	w := WriterToSeq(func(seq Seq) error {
+		return consumeErr
+	})
+	if _, err := w.Write([]byte("one")); err != consumeErr {
+		t.Fatalf("unexpected error from Write; got %v want %v", err, consumeErr)
+	}
+	if err := w.Close(); err != consumeErr {
+		t.Fatalf("unexpected error from Close; got %v want %v", err, consumeErr)
+	}
+}
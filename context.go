@@ -0,0 +1,79 @@
+package ioseq
+
+import (
+	"context"
+)
+
+// SeqWithContext returns a [Seq] that yields the same elements as seq
+// until ctx is cancelled, at which point it yields ctx.Err() and stops
+// iterating over seq.
+//
+// The context is checked before each element is yielded, so a
+// producer that is blocked waiting for data will not be interrupted;
+// such producers should observe the context themselves.
+//
+// When used with [PipeSeqThrough], the context can be applied to
+// either side of the pipe. Wrapping the input, as in
+//
+//	PipeSeqThrough(SeqWithContext(ctx, seq), f)
+//
+// means that on cancellation the writer returned by f is not closed
+// and the resulting sequence yields ctx.Err(), while wrapping the
+// output stops the whole pipeline when the consumer would next see
+// a chunk, including any data still being flushed by the writer's
+// Close method.
+func SeqWithContext(ctx context.Context, seq Seq) Seq {
+	return func(yield func([]byte, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(nil, err)
+			return
+		}
+		for data, err := range seq {
+			if err == nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					yield(nil, ctxErr)
+					return
+				}
+			}
+			if !yield(data, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// DrainOnCancelSeq is an alternative to [SeqWithContext] for when data
+// that has already been produced should not be lost on cancellation.
+// It returns a [Seq] that yields the output of t applied to seq, except
+// that when ctx is cancelled, the input to t ends cleanly rather than
+// with an error, so that t can flush any data that it has buffered,
+// such as the remainder of a compressed stream or chunks held by
+// [AsyncSeq]. Once t's output has been yielded, ctx.Err() is yielded.
+//
+// As with SeqWithContext, the context is checked before each element
+// of seq is passed on.
+func DrainOnCancelSeq(ctx context.Context, seq Seq, t Transform) Seq {
+	return func(yield func([]byte, error) bool) {
+		var cancelErr error
+		in := func(yield func([]byte, error) bool) {
+			for data, err := range seq {
+				if err == nil {
+					if cancelErr = ctx.Err(); cancelErr != nil {
+						return
+					}
+				}
+				if !yield(data, err) || err != nil {
+					return
+				}
+			}
+		}
+		for data, err := range t(in) {
+			if !yield(data, err) || err != nil {
+				return
+			}
+		}
+		if cancelErr != nil {
+			yield(nil, cancelErr)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package ioseq
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestSeqWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var got []string
+	var gotErr error
+	for data, err := range SeqWithContext(ctx, seqOfStrings("a", "b", "c")) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		got = append(got, string(data))
+		if len(got) == 2 {
+			cancel()
+		}
+	}
+	if want := []string{"a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if gotErr != context.Canceled {
+		t.Errorf("unexpected error %v", gotErr)
+	}
+}
+
+func TestSeqWithContextAlreadyCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	in := func(yield func([]byte, error) bool) {
+		called = true
+	}
+	_, err := collectStrings(SeqWithContext(ctx, in))
+	if err != context.Canceled {
+		t.Errorf("unexpected error %v", err)
+	}
+	if called {
+		t.Errorf("underlying sequence was iterated")
+	}
+}
+
+func TestSeqWithContextPipeSeqThrough(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	closed := false
+	seq := PipeSeqThrough(SeqWithContext(ctx, seqOfStrings("a")), func(w io.Writer) io.WriteCloser {
+		return closeFunc{w, func() { closed = true }}
+	})
+	_, err := collectStrings(seq)
+	if err != context.Canceled {
+		t.Errorf("unexpected error %v", err)
+	}
+	if !closed {
+		t.Errorf("writer was not closed")
+	}
+}
+
+type closeFunc struct {
+	io.Writer
+	f func()
+}
+
+func (c closeFunc) Close() error {
+	c.f()
+	return nil
+}
+
+func TestDrainOnCancelSeq(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src := func(yield func([]byte, error) bool) {
+		for i := range 10 {
+			if i == 3 {
+				cancel()
+			}
+			if !yield([]byte(fmt.Sprint(i)), nil) {
+				return
+			}
+		}
+	}
+	// The transform buffers all its input and yields it
+	// at the end, so no output would be seen without draining.
+	buffering := func(seq Seq) Seq {
+		return func(yield func([]byte, error) bool) {
+			data, err := ReadAllSeq(seq)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(data, nil) {
+				return
+			}
+			yield([]byte("!"), nil)
+		}
+	}
+	got, err := collectStrings(DrainOnCancelSeq(ctx, src, buffering))
+	if want := []string{"012", "!"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected data; got %q want %q", got, want)
+	}
+	if err != context.Canceled {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestDrainOnCancelSeqNotCancelled(t *testing.T) {
+	got, err := collectStrings(DrainOnCancelSeq(context.Background(), seqOfStrings("a", "b"), func(seq Seq) Seq {
+		return AsyncSeq(seq, 1)
+	}))
+	if want := []string{"a", "b"}; !slices.Equal(got, want) || err != nil {
+		t.Errorf("unexpected result %q, %v", got, err)
+	}
+}
@@ -0,0 +1,156 @@
+package ioseq
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// CopySeqN is like [io.CopyN]: it copies n bytes (or until an error)
+// from seq to w. It returns the number of bytes copied and the earliest
+// error encountered while copying. On return, written == n if and only
+// if err == nil. If seq ends before n bytes have been copied, the
+// error is [io.EOF].
+//
+// The iteration over seq is stopped after the chunk holding the n'th
+// byte; the remainder of that chunk is discarded, so a Seq that reads
+// from an underlying source may have consumed more than n bytes from
+// it.
+func CopySeqN(w io.Writer, seq Seq, n int64) (written int64, err error) {
+	written, err = CopySeq(w, LimitSeq(seq, n))
+	if written == n {
+		return n, nil
+	}
+	if written < n && err == nil {
+		// seq ended early.
+		err = io.EOF
+	}
+	return written, err
+}
+
+// CopySeqFlush is like [CopySeq] except that it flushes w after
+// writing each chunk, so that data is passed on as soon as it is
+// available rather than being held in a buffer. This is useful when
+// latency matters, for example when streaming server-sent events or
+// progress updates over HTTP.
+//
+// The writer is flushed if it implements [http.Flusher] or has a
+// Flush method that returns an error, such as [*bufio.Writer]; an
+// error from Flush stops the copy. If w has no Flush method,
+// CopySeqFlush is equivalent to CopySeq.
+func CopySeqFlush(w io.Writer, seq Seq) (int64, error) {
+	var flush func() error
+	switch fw := w.(type) {
+	case interface{ Flush() error }:
+		flush = fw.Flush
+	case http.Flusher:
+		flush = func() error {
+			fw.Flush()
+			return nil
+		}
+	default:
+		return CopySeq(w, seq)
+	}
+	tot := int64(0)
+	for data, err := range seq {
+		if err != nil {
+			return tot, err
+		}
+		n, err := w.Write(data)
+		tot += int64(n)
+		if err == nil && n != len(data) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			return tot, err
+		}
+		if err := flush(); err != nil {
+			return tot, err
+		}
+	}
+	return tot, nil
+}
+
+// DiscardSeq consumes all of seq, discarding the data, and returns
+// the number of bytes it held along with the error that terminated
+// it, if any. This can be used to find the size of some generated
+// content or to drain a sequence so that the underlying resources,
+// such as an HTTP connection, can be reused.
+func DiscardSeq(seq Seq) (int64, error) {
+	n := int64(0)
+	for data, err := range seq {
+		if err != nil {
+			return n, err
+		}
+		n += int64(len(data))
+	}
+	return n, nil
+}
+
+// MultiCopySeq is like [CopySeq] but writes each chunk from seq to all
+// of the given writers in turn, similarly to using [io.MultiWriter].
+// It returns the number of bytes read from seq that have been
+// written to all the writers.
+//
+// If a write fails, MultiCopySeq stops and returns a
+// [*MultiWriteError] identifying the writer that failed.
+func MultiCopySeq(ws []io.Writer, seq Seq) (int64, error) {
+	tot := int64(0)
+	for data, err := range seq {
+		if err != nil {
+			return tot, err
+		}
+		for i, w := range ws {
+			n, err := w.Write(data)
+			if err == nil && n != len(data) {
+				err = io.ErrShortWrite
+			}
+			if err != nil {
+				return tot, &MultiWriteError{
+					Index: i,
+					Err:   err,
+				}
+			}
+		}
+		tot += int64(len(data))
+	}
+	return tot, nil
+}
+
+// ReadAllSeq returns all the data from seq in a single slice,
+// along with any error that terminated it. It is the Seq analogue of
+// [io.ReadAll]; like io.ReadAll, it returns a non-nil slice even if
+// seq is empty.
+func ReadAllSeq(seq Seq) ([]byte, error) {
+	return AppendSeq(make([]byte, 0, 512), seq)
+}
+
+// AppendSeq appends all the data from seq to dst and returns the
+// extended slice, along with any error that terminated seq.
+// Data yielded before an error is still appended.
+func AppendSeq(dst []byte, seq Seq) ([]byte, error) {
+	for data, err := range seq {
+		if err != nil {
+			return dst, err
+		}
+		dst = append(dst, data...)
+	}
+	return dst, nil
+}
+
+// MultiWriteError is the error returned by [MultiCopySeq]
+// when one of its writers fails.
+type MultiWriteError struct {
+	// Index holds the index of the writer that failed.
+	Index int
+	// Err holds the error returned by the writer.
+	Err error
+}
+
+func (e *MultiWriteError) Error() string {
+	return fmt.Sprintf("write to writer %d: %v", e.Index, e.Err)
+}
+
+func (e *MultiWriteError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,204 @@
+package ioseq
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestMultiCopySeq(t *testing.T) {
+	var b0, b1 bytes.Buffer
+	n, err := MultiCopySeq([]io.Writer{&b0, &b1}, seqOfStrings("foo", "bar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 6 {
+		t.Errorf("unexpected count; got %d want 6", n)
+	}
+	if b0.String() != "foobar" || b1.String() != "foobar" {
+		t.Errorf("unexpected content %q, %q", b0.String(), b1.String())
+	}
+}
+
+func TestMultiCopySeqWriterError(t *testing.T) {
+	var b0 bytes.Buffer
+	failErr := errors.New("fail")
+	failing := &limitedWriter{n: 4, err: failErr}
+	n, err := MultiCopySeq([]io.Writer{&b0, failing}, seqOfStrings("foo", "bar"))
+	if n != 3 {
+		t.Errorf("unexpected count; got %d want 3", n)
+	}
+	var merr *MultiWriteError
+	if !errors.As(err, &merr) {
+		t.Fatalf("unexpected error type %T", err)
+	}
+	if merr.Index != 1 {
+		t.Errorf("unexpected writer index; got %d want 1", merr.Index)
+	}
+	if !errors.Is(err, failErr) {
+		t.Errorf("error %v does not wrap writer error", err)
+	}
+}
+
+func TestReadAllSeq(t *testing.T) {
+	data, err := ReadAllSeq(seqOfStrings("foo", "", "bar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "foobar"; got != want {
+		t.Errorf("unexpected result; got %q want %q", got, want)
+	}
+	data, err = ReadAllSeq(seqOfStrings())
+	if err != nil || data == nil || len(data) != 0 {
+		t.Errorf("unexpected result for empty sequence: %q, %v", data, err)
+	}
+}
+
+func TestAppendSeq(t *testing.T) {
+	in := func(yield func([]byte, error) bool) {
+		if !yield([]byte("foo"), nil) {
+			return
+		}
+		yield(nil, fmt.Errorf("some error"))
+	}
+	data, err := AppendSeq([]byte("prefix:"), in)
+	if got, want := string(data), "prefix:foo"; got != want {
+		t.Errorf("unexpected result; got %q want %q", got, want)
+	}
+	if err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// limitedWriter accepts up to n bytes and then
+// fails with err.
+type limitedWriter struct {
+	n   int
+	err error
+}
+
+func (w *limitedWriter) Write(buf []byte) (int, error) {
+	if len(buf) <= w.n {
+		w.n -= len(buf)
+		return len(buf), nil
+	}
+	n := w.n
+	w.n = 0
+	return n, w.err
+}
+
+func TestCopySeqN(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := CopySeqN(&buf, seqOfStrings("hello", " ", "world"), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 8 || buf.String() != "hello wo" {
+		t.Errorf("unexpected result %d, %q", n, buf.String())
+	}
+
+	buf.Reset()
+	n, err = CopySeqN(&buf, seqOfStrings("hello"), 8)
+	if err != io.EOF {
+		t.Errorf("unexpected error; got %v want %v", err, io.EOF)
+	}
+	if n != 5 || buf.String() != "hello" {
+		t.Errorf("unexpected result %d, %q", n, buf.String())
+	}
+
+	// The sequence isn't consumed beyond n bytes.
+	seq := func(yield func([]byte, error) bool) {
+		if !yield([]byte("hello"), nil) {
+			return
+		}
+		t.Errorf("sequence consumed beyond limit")
+	}
+	if _, err := CopySeqN(io.Discard, seq, 5); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCopySeqFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	var flushed []string
+	fw := &flushRecorder{ResponseRecorder: rec, flushed: &flushed}
+	n, err := CopySeqFlush(fw, seqOfStrings("one", "two"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 6 {
+		t.Errorf("unexpected count %d", n)
+	}
+	if want := []string{"one", "onetwo"}; !slices.Equal(flushed, want) {
+		t.Errorf("unexpected flushes; got %q want %q", flushed, want)
+	}
+
+	// A Flush method that returns an error stops the copy.
+	bw := bufio.NewWriterSize(&limitedWriter{n: 3}, 16)
+	_, err = CopySeqFlush(bw, seqOfStrings("one", "two", "three"))
+	if err != io.ErrShortWrite {
+		t.Fatalf("unexpected error; got %v want %v", err, io.ErrShortWrite)
+	}
+	if got, want := bw.Buffered(), 3; got != want {
+		t.Errorf("unexpected buffered count; got %d want %d", got, want)
+	}
+}
+
+// flushRecorder records the body contents each time it's flushed.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushed *[]string
+}
+
+func (r *flushRecorder) Flush() {
+	*r.flushed = append(*r.flushed, r.Body.String())
+}
+
+func TestCopySeqShortWrite(t *testing.T) {
+	n, err := CopySeq(&limitedWriter{n: 5}, seqOfStrings("foo", "bar", "baz"))
+	if err != io.ErrShortWrite {
+		t.Fatalf("unexpected error; got %v want %v", err, io.ErrShortWrite)
+	}
+	if n != 5 {
+		t.Errorf("unexpected count %d", n)
+	}
+}
+
+func TestCopySeqCounts(t *testing.T) {
+	writeErr := errors.New("write error")
+	read, written, err := CopySeqCounts(&limitedWriter{n: 5, err: writeErr}, seqOfStrings("foo", "bar", "baz"))
+	if err != writeErr {
+		t.Fatalf("unexpected error; got %v want %v", err, writeErr)
+	}
+	if read != 6 || written != 5 {
+		t.Errorf("unexpected counts; got read %d written %d; want 6, 5", read, written)
+	}
+
+	read, written, err = CopySeqCounts(io.Discard, seqOfStrings("foo", "bar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != 6 || written != 6 {
+		t.Errorf("unexpected counts; got read %d written %d; want 6, 6", read, written)
+	}
+}
+
+func TestDiscardSeq(t *testing.T) {
+	n, err := DiscardSeq(seqOfStrings("foo", "", "barbaz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 9 {
+		t.Errorf("unexpected count %d", n)
+	}
+	seqErr := errors.New("some error")
+	n, err = DiscardSeq(ConcatSeqs(seqOfStrings("foo"), ErrorSeq(seqErr)))
+	if err != seqErr || n != 3 {
+		t.Errorf("unexpected result %d, %v", n, err)
+	}
+}
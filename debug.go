@@ -0,0 +1,157 @@
+package ioseq
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+)
+
+// poison holds the pattern written over chunks by [PoisonSeq].
+const poison = "\xde\xad\xbe\xef"
+
+// PoisonSeq returns a [Seq] that yields the same data as seq, but
+// copies each chunk into a new buffer and overwrites that buffer with
+// a repeating 0xdeadbeef pattern as soon as the consumer has finished
+// with it, that is, when yield returns. The data yielded by seq itself
+// is not modified.
+//
+// PoisonSeq is intended for use in tests: a consumer that retains a
+// slice beyond the iteration that yielded it, in violation of the
+// [Seq] contract, will see garbage rather than data that happens to
+// be correct until the producer reuses its buffer.
+func PoisonSeq(seq Seq) Seq {
+	return func(yield func([]byte, error) bool) {
+		for data, err := range seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			buf := make([]byte, len(data))
+			copy(buf, data)
+			ok := yield(buf, nil)
+			for i := range buf {
+				buf[i] = poison[i%len(poison)]
+			}
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// ContractError describes a violation of the [Seq] contract
+// detected by [ValidateSeq].
+type ContractError struct {
+	// Index holds the index of the offending element
+	// in the sequence, counting from zero.
+	Index int
+
+	// Problem describes the violation.
+	Problem string
+
+	// Err holds the error yielded by the producer,
+	// if any.
+	Err error
+}
+
+func (e *ContractError) Error() string {
+	msg := fmt.Sprintf("ioseq: sequence contract violation at element %d: %s", e.Index, e.Problem)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ContractError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateSeq returns a [Seq] that yields the same elements as seq,
+// checking that seq obeys the [Seq] contract.
+//
+// If seq yields (nil, nil), or both data and an error, the returned
+// sequence yields a [*ContractError] and stops. Violations that cannot
+// be reported to the consumer, such as seq calling yield again after
+// yield has returned false, after yielding an error, or after the
+// iteration has finished, cause a panic with a [*ContractError].
+//
+// ValidateSeq is useful when testing producers or integrating
+// producers from third parties.
+func ValidateSeq(seq Seq) Seq {
+	return func(yield func([]byte, error) bool) {
+		index := 0
+		stopped := ""
+		seq(func(data []byte, err error) bool {
+			if stopped != "" {
+				panic(&ContractError{
+					Index:   index,
+					Problem: "yield called " + stopped,
+				})
+			}
+			var problem string
+			switch {
+			case data == nil && err == nil:
+				problem = "nil data and nil error"
+			case data != nil && err != nil:
+				problem = "non-nil data with non-nil error"
+			}
+			if problem != "" {
+				stopped = "after contract violation"
+				yield(nil, &ContractError{
+					Index:   index,
+					Problem: problem,
+					Err:     err,
+				})
+				return false
+			}
+			index++
+			if err != nil {
+				stopped = "after yielding an error"
+				yield(nil, err)
+				return false
+			}
+			if !yield(data, nil) {
+				stopped = "after yield returned false"
+				return false
+			}
+			return true
+		})
+		stopped = "after the iteration finished"
+	}
+}
+
+// CheckGoroutineSeq returns a [Seq] that yields the same elements as
+// seq, but panics with a descriptive message if seq calls yield from
+// a goroutine other than the one running the iteration. This often
+// happens when a writer returned by [SeqWriter] is passed to code that
+// writes to it from another goroutine, and otherwise results in
+// confusing failures from deep inside the iteration machinery.
+//
+// Finding the current goroutine is relatively expensive, so
+// CheckGoroutineSeq is intended for debugging and tests.
+func CheckGoroutineSeq(seq Seq) Seq {
+	return func(yield func([]byte, error) bool) {
+		id := goroutineID()
+		seq(func(data []byte, err error) bool {
+			if yid := goroutineID(); yid != id {
+				panic(fmt.Sprintf("ioseq: yield called from goroutine %d but the sequence is being iterated in goroutine %d", yid, id))
+			}
+			return yield(data, err)
+		})
+	}
+}
+
+// goroutineID returns the id of the current goroutine, as
+// found at the start of the output of [runtime.Stack].
+func goroutineID() uint64 {
+	var buf [64]byte
+	stack := buf[:runtime.Stack(buf[:], false)]
+	stack = bytes.TrimPrefix(stack, []byte("goroutine "))
+	stack, _, _ = bytes.Cut(stack, []byte(" "))
+	id, err := strconv.ParseUint(string(stack), 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("ioseq: cannot determine goroutine id: %v", err))
+	}
+	return id
+}
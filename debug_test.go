@@ -0,0 +1,181 @@
+package ioseq
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestPoisonSeq(t *testing.T) {
+	seqErr := errors.New("some error")
+	input := func(yield func([]byte, error) bool) {
+		if !yield([]byte("hello"), nil) {
+			return
+		}
+		yield(nil, seqErr)
+	}
+	var retained []byte
+	var gotErr error
+	for data, err := range PoisonSeq(input) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		if string(data) != "hello" {
+			t.Errorf("unexpected data %q", data)
+		}
+		retained = data
+	}
+	if gotErr != seqErr {
+		t.Errorf("unexpected error %v", gotErr)
+	}
+	if want := []byte("\xde\xad\xbe\xef\xde"); !bytes.Equal(retained, want) {
+		t.Errorf("retained data not poisoned; got %q want %q", retained, want)
+	}
+}
+
+func TestPoisonSeqDoesNotModifySource(t *testing.T) {
+	src := []byte("hello")
+	for range PoisonSeq(SeqFromBytes(src)) {
+	}
+	if string(src) != "hello" {
+		t.Errorf("source data modified: %q", src)
+	}
+}
+
+var validateSeqTests = []struct {
+	testName  string
+	seq       Seq
+	wantData  string
+	wantErr   string
+	wantPanic string
+}{{
+	testName: "Valid",
+	seq:      seqOfStrings("a", "b"),
+	wantData: "ab",
+}, {
+	testName: "NilNil",
+	seq: func(yield func([]byte, error) bool) {
+		yield([]byte("a"), nil)
+		yield(nil, nil)
+	},
+	wantData: "a",
+	wantErr:  "ioseq: sequence contract violation at element 1: nil data and nil error",
+}, {
+	testName: "DataWithError",
+	seq: func(yield func([]byte, error) bool) {
+		yield([]byte("a"), errors.New("oops"))
+	},
+	wantErr: "ioseq: sequence contract violation at element 0: non-nil data with non-nil error: oops",
+}, {
+	testName: "YieldAfterError",
+	seq: func(yield func([]byte, error) bool) {
+		yield(nil, errors.New("oops"))
+		yield([]byte("a"), nil)
+	},
+	wantErr:   "oops",
+	wantPanic: "ioseq: sequence contract violation at element 1: yield called after yielding an error",
+}, {
+	testName: "YieldAfterFalse",
+	seq: func(yield func([]byte, error) bool) {
+		yield([]byte("a"), nil)
+		yield([]byte("b"), nil)
+	},
+	wantData:  "a",
+	wantPanic: "ioseq: sequence contract violation at element 1: yield called after yield returned false",
+}}
+
+func TestValidateSeq(t *testing.T) {
+	for _, test := range validateSeqTests {
+		t.Run(test.testName, func(t *testing.T) {
+			var data []byte
+			var gotErr error
+			var panicVal any
+			func() {
+				defer func() {
+					panicVal = recover()
+				}()
+				for chunk, err := range ValidateSeq(test.seq) {
+					if err != nil {
+						gotErr = err
+						continue
+					}
+					data = append(data, chunk...)
+					if test.wantPanic != "" && test.wantErr == "" {
+						break
+					}
+				}
+			}()
+			if string(data) != test.wantData {
+				t.Errorf("unexpected data %q", data)
+			}
+			if test.wantErr == "" {
+				if gotErr != nil {
+					t.Errorf("unexpected error %v", gotErr)
+				}
+			} else if gotErr == nil || gotErr.Error() != test.wantErr {
+				t.Errorf("unexpected error; got %v want %q", gotErr, test.wantErr)
+			}
+			if test.wantPanic == "" {
+				if panicVal != nil {
+					t.Errorf("unexpected panic %v", panicVal)
+				}
+				return
+			}
+			perr, ok := panicVal.(*ContractError)
+			if !ok {
+				t.Fatalf("unexpected panic value %#v", panicVal)
+			}
+			if perr.Error() != test.wantPanic {
+				t.Errorf("unexpected panic; got %q want %q", perr, test.wantPanic)
+			}
+		})
+	}
+}
+
+func TestValidateSeqUnwrap(t *testing.T) {
+	seqErr := errors.New("oops")
+	for _, err := range ValidateSeq(func(yield func([]byte, error) bool) {
+		yield([]byte("a"), seqErr)
+	}) {
+		if !errors.Is(err, seqErr) {
+			t.Errorf("error %v does not wrap %v", err, seqErr)
+		}
+	}
+}
+
+func TestCheckGoroutineSeq(t *testing.T) {
+	seq := CheckGoroutineSeq(func(yield func([]byte, error) bool) {
+		w := SeqWriter(yield, nil)
+		w.Write([]byte("hello"))
+		done := make(chan any)
+		go func() {
+			defer func() {
+				done <- recover()
+			}()
+			w.Write([]byte("world"))
+		}()
+		if e := <-done; e != nil {
+			yield(nil, fmt.Errorf("%v", e))
+		}
+	})
+	// Check both with range and with a reader, which
+	// uses iter.Pull2 and hence a different goroutine.
+	data, err := ReadAllSeq(seq)
+	if string(data) != "hello" {
+		t.Errorf("unexpected data %q", data)
+	}
+	if err == nil || !strings.HasPrefix(err.Error(), "ioseq: yield called from goroutine ") {
+		t.Errorf("unexpected error %v", err)
+	}
+	data, err = io.ReadAll(ReaderFromSeq(seq))
+	if string(data) != "hello" {
+		t.Errorf("unexpected data %q", data)
+	}
+	if err == nil || !strings.HasPrefix(err.Error(), "ioseq: yield called from goroutine ") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package ioseq
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"sync"
+)
+
+type decompressor struct {
+	name      string
+	magic     string
+	newReader func(io.Reader) (io.Reader, error)
+}
+
+var (
+	decompressorsMu sync.Mutex
+	decompressors   []decompressor
+	maxMagicLen     int
+)
+
+func init() {
+	RegisterDecompressor("gzip", "\x1f\x8b", func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	})
+	// The first two bytes of a zlib stream form a checksum; these are
+	// the values produced for the default window size at each of the
+	// compression levels.
+	for _, magic := range []string{"\x78\x01", "\x78\x5e", "\x78\x9c", "\x78\xda"} {
+		RegisterDecompressor("zlib", magic, func(r io.Reader) (io.Reader, error) {
+			return zlib.NewReader(r)
+		})
+	}
+	RegisterDecompressor("bzip2", "BZh", func(r io.Reader) (io.Reader, error) {
+		return bzip2.NewReader(r), nil
+	})
+}
+
+// RegisterDecompressor registers a compression format for use by
+// [DecompressSeq]. The name is the name of the format, such as "gzip";
+// magic is the prefix that identifies data in the format; a "?" in
+// magic matches any byte. The newReader function returns a reader that
+// decompresses data read from its argument; if it also implements
+// [io.Closer], it will be closed when the decompressed data has been
+// consumed.
+//
+// Formats registered later take precedence over those registered
+// earlier when their magic strings both match.
+func RegisterDecompressor(name, magic string, newReader func(io.Reader) (io.Reader, error)) {
+	decompressorsMu.Lock()
+	defer decompressorsMu.Unlock()
+	decompressors = append(decompressors, decompressor{
+		name:      name,
+		magic:     magic,
+		newReader: newReader,
+	})
+	maxMagicLen = max(maxMagicLen, len(magic))
+}
+
+// LookupDecompressor returns the function registered with
+// [RegisterDecompressor] for the named format, and whether there is
+// one. If several have been registered with the same name, the most
+// recently registered one is returned.
+func LookupDecompressor(name string) (newReader func(io.Reader) (io.Reader, error), ok bool) {
+	decompressorsMu.Lock()
+	defer decompressorsMu.Unlock()
+	for i := len(decompressors) - 1; i >= 0; i-- {
+		if d := decompressors[i]; d.name == name {
+			return d.newReader, true
+		}
+	}
+	return nil, false
+}
+
+// matchDecompressor returns the registered decompressor whose magic
+// string matches the start of head, and whether there is one.
+func matchDecompressor(head []byte) (decompressor, bool) {
+	decompressorsMu.Lock()
+	defer decompressorsMu.Unlock()
+	for i := len(decompressors) - 1; i >= 0; i-- {
+		if d := decompressors[i]; matchMagic(d.magic, head) {
+			return d, true
+		}
+	}
+	return decompressor{}, false
+}
+
+func matchMagic(magic string, head []byte) bool {
+	if len(head) < len(magic) {
+		return false
+	}
+	for i := range len(magic) {
+		if magic[i] != '?' && magic[i] != head[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// DecompressSeq returns a [Seq] that inspects the first few bytes of
+// seq and, if they identify one of the compression formats registered
+// with [RegisterDecompressor], yields the decompressed data. Data in
+// an unrecognized format is passed through unchanged. The gzip, zlib
+// and bzip2 formats are registered by default.
+func DecompressSeq(seq Seq) Seq {
+	return func(yield func([]byte, error) bool) {
+		decompressorsMu.Lock()
+		n := maxMagicLen
+		decompressorsMu.Unlock()
+		head, rest, err := HeadSeq(seq, n)
+		// Make sure that rest is always iterated over, as
+		// required by HeadSeq, even if the decompressor
+		// doesn't read anything.
+		defer func() {
+			for range rest {
+				break
+			}
+		}()
+		d, ok := matchDecompressor(head)
+		if err != nil || !ok {
+			for data, err := range rest {
+				if !yield(data, err) || err != nil {
+					return
+				}
+			}
+			return
+		}
+		r := ReaderFromSeq(rest)
+		defer r.Close()
+		dr, err := d.newReader(r)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if c, ok := dr.(io.Closer); ok {
+			defer c.Close()
+		}
+		for data, err := range SeqFromReader(dr, DefaultBufferSize) {
+			if !yield(data, err) || err != nil {
+				return
+			}
+		}
+	}
+}
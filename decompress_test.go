@@ -0,0 +1,116 @@
+package ioseq
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+	"testing"
+)
+
+// bzip2Hello holds "hello, bzip2\n" compressed with bzip2.
+const bzip2Hello = "BZh91AY&SY\xb1#\xdeC\x00\x00\x03Y\x80\x00\x10@\x04\x10\x00\x12d\xc0\x10 \x001\x03@\xd0 \x01\xa6\x91\x03\xabl\x82\x84\xf8\xbb\x92)\xc2\x84\x85\x89\x1e\xf2\x18"
+
+var decompressSeqTests = []struct {
+	testName string
+	in       func() string
+	want     string
+}{{
+	testName: "Gzip",
+	in: func() string {
+		return compressString(func(w io.Writer) io.WriteCloser {
+			return gzip.NewWriter(w)
+		}, "hello, gzip\n")
+	},
+	want: "hello, gzip\n",
+}, {
+	testName: "Zlib",
+	in: func() string {
+		return compressString(func(w io.Writer) io.WriteCloser {
+			return zlib.NewWriter(w)
+		}, "hello, zlib\n")
+	},
+	want: "hello, zlib\n",
+}, {
+	testName: "Bzip2",
+	in: func() string {
+		return bzip2Hello
+	},
+	want: "hello, bzip2\n",
+}, {
+	testName: "Unknown",
+	in: func() string {
+		return "just some text"
+	},
+	want: "just some text",
+}, {
+	testName: "Short",
+	in: func() string {
+		return "\x1f"
+	},
+	want: "\x1f",
+}, {
+	testName: "Empty",
+	in: func() string {
+		return ""
+	},
+	want: "",
+}}
+
+func TestDecompressSeq(t *testing.T) {
+	for _, test := range decompressSeqTests {
+		t.Run(test.testName, func(t *testing.T) {
+			// Split the input into small chunks to make sure
+			// that the magic number detection works across
+			// chunk boundaries.
+			var chunks []string
+			for s := test.in(); len(s) > 0; s = s[1:] {
+				chunks = append(chunks, s[:1])
+			}
+			got, err := collectStrings(DecompressSeq(seqOfStrings(chunks...)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(got, ""); got != test.want {
+				t.Errorf("unexpected result; got %q want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDecompressSeqCorrupt(t *testing.T) {
+	in := compressString(func(w io.Writer) io.WriteCloser {
+		return gzip.NewWriter(w)
+	}, "hello, gzip\n")
+	_, err := collectStrings(DecompressSeq(seqOfStrings(in[:len(in)-4])))
+	if err == nil {
+		t.Fatalf("expected error from truncated stream")
+	}
+}
+
+func compressString(newWriter func(io.Writer) io.WriteCloser, s string) string {
+	var buf bytes.Buffer
+	w := newWriter(&buf)
+	w.Write([]byte(s))
+	w.Close()
+	return buf.String()
+}
+
+func TestLookupDecompressor(t *testing.T) {
+	newReader, ok := LookupDecompressor("bzip2")
+	if !ok {
+		t.Fatalf("bzip2 not found")
+	}
+	r, err := newReader(strings.NewReader(bzip2Hello))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	if string(data) != "hello, bzip2\n" || err != nil {
+		t.Errorf("unexpected result %q, %v", data, err)
+	}
+	if _, ok := LookupDecompressor("unknown"); ok {
+		t.Errorf("unexpected decompressor for unknown format")
+	}
+}
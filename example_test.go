@@ -38,14 +38,19 @@ func ExamplePipeSeqThrough() {
 			}
 		}
 	}
-	n := 0
-	for data, err := range ioseq.PipeSeqThrough(zeros, gzip.NewWriter) {
-		if err != nil {
-			panic(err)
-		}
-		n += len(data)
+	// Compress the data, then decompress it again to check
+	// that it round-trips.
+	r := ioseq.ReaderFromSeq(ioseq.PipeSeqThrough(zeros, gzip.NewWriter))
+	defer r.Close()
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		panic(err)
+	}
+	n, err := io.Copy(io.Discard, zr)
+	if err != nil {
+		panic(err)
 	}
 	fmt.Println(n)
 	// Output:
-	// 7988
+	// 8192000
 }
@@ -0,0 +1,83 @@
+package ioseq
+
+import (
+	"bytes"
+	"iter"
+	"net/http"
+)
+
+// HeadSeq reads up to n bytes from the start of seq and returns them
+// along with a sequence, rest, that yields all the data from seq,
+// including the bytes already read. This makes it straightforward to
+// inspect the start of a stream before deciding how to process it.
+//
+// The returned head slice is a copy owned by the caller, so modifying
+// it does not affect the data yielded by rest. If seq holds fewer
+// than n bytes, head will be shorter than n. If seq yields an error
+// while the head is being read, that error is returned and rest will
+// yield it after the data preceding it.
+//
+// The caller must iterate over rest exactly once, even if only to
+// break out of the loop immediately, otherwise resources associated
+// with seq may not be released.
+func HeadSeq(seq Seq, n int) (head []byte, rest Seq, err error) {
+	next, stop := iter.Pull2(seq)
+	var buf []byte
+	for len(buf) < n {
+		data, err1, ok := next()
+		if !ok {
+			break
+		}
+		if err1 != nil {
+			err = err1
+			break
+		}
+		buf = append(buf, data...)
+	}
+	// Copy the head so that the caller can't affect
+	// the data yielded by rest.
+	head = bytes.Clone(buf[:min(n, len(buf))])
+	rest = func(yield func([]byte, error) bool) {
+		defer stop()
+		if len(buf) > 0 {
+			data := buf
+			buf = nil
+			if !yield(data, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for {
+			data, err, ok := next()
+			if !ok {
+				return
+			}
+			if !yield(data, err) || err != nil {
+				return
+			}
+		}
+	}
+	return head, rest, err
+}
+
+// sniffLen is the maximum number of bytes considered
+// by [http.DetectContentType].
+const sniffLen = 512
+
+// SniffSeq uses [http.DetectContentType] to determine the content type
+// of the data in seq, reading at most the first 512 bytes. It returns
+// the content type along with a sequence that yields all the data
+// from seq, as for [HeadSeq]; the same rules apply to rest.
+//
+// If seq yields an error before enough data has been read, that error
+// is returned and contentType will be empty.
+func SniffSeq(seq Seq) (contentType string, rest Seq, err error) {
+	head, rest, err := HeadSeq(seq, sniffLen)
+	if err != nil {
+		return "", rest, err
+	}
+	return http.DetectContentType(head), rest, nil
+}
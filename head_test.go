@@ -0,0 +1,135 @@
+package ioseq
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var headSeqTests = []struct {
+	testName string
+	in       []string
+	n        int
+	wantHead string
+}{{
+	testName: "Empty",
+	n:        4,
+	wantHead: "",
+}, {
+	testName: "Short",
+	in:       []string{"ab", "c"},
+	n:        4,
+	wantHead: "abc",
+}, {
+	testName: "ExactChunk",
+	in:       []string{"ab", "cd", "ef"},
+	n:        4,
+	wantHead: "abcd",
+}, {
+	testName: "SplitChunk",
+	in:       []string{"ab", "cdef", "gh"},
+	n:        3,
+	wantHead: "abc",
+}}
+
+func TestHeadSeq(t *testing.T) {
+	for _, test := range headSeqTests {
+		t.Run(test.testName, func(t *testing.T) {
+			head, rest, err := HeadSeq(seqOfStrings(test.in...), test.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(head) != test.wantHead {
+				t.Errorf("unexpected head; got %q want %q", head, test.wantHead)
+			}
+			got, err := collectStrings(rest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := strings.Join(got, ""), strings.Join(test.in, ""); got != want {
+				t.Errorf("unexpected rest; got %q want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestHeadSeqError(t *testing.T) {
+	in := func(yield func([]byte, error) bool) {
+		if !yield([]byte("ab"), nil) {
+			return
+		}
+		yield(nil, fmt.Errorf("some error"))
+	}
+	head, rest, err := HeadSeq(in, 4)
+	if err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if string(head) != "ab" {
+		t.Errorf("unexpected head %q", head)
+	}
+	got, err := collectStrings(rest)
+	if want := []string{"ab"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error from rest %v", err)
+	}
+}
+
+func TestHeadSeqModifyHead(t *testing.T) {
+	head, rest, err := HeadSeq(seqOfStrings("abc", "def"), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(head, "XXXX")
+	got, err := collectStrings(rest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(got, ""); got != "abcdef" {
+		t.Errorf("unexpected data from rest %q", got)
+	}
+}
+
+func TestHeadSeqStopsSource(t *testing.T) {
+	stopped := false
+	in := func(yield func([]byte, error) bool) {
+		defer func() {
+			stopped = true
+		}()
+		for {
+			if !yield([]byte("abc"), nil) {
+				return
+			}
+		}
+	}
+	_, rest, err := HeadSeq(in, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range rest {
+		break
+	}
+	if !stopped {
+		t.Errorf("source was not stopped")
+	}
+}
+
+func TestSniffSeq(t *testing.T) {
+	in := seqOfStrings("<!DOCTYPE ", "html><html>", "<body>hello</body></html>")
+	contentType, rest, err := SniffSeq(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "text/html; charset=utf-8"; contentType != want {
+		t.Errorf("unexpected content type; got %q want %q", contentType, want)
+	}
+	got, err := collectStrings(rest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(got, ""), "<!DOCTYPE html><html><body>hello</body></html>"; got != want {
+		t.Errorf("unexpected rest; got %q want %q", got, want)
+	}
+}
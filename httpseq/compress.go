@@ -0,0 +1,100 @@
+package httpseq
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/rogpeppe/ioseq"
+)
+
+// CompressTransport is an [http.RoundTripper] that compresses the
+// bodies of outgoing requests, setting the Content-Encoding header
+// accordingly. The compression happens as the body is sent, using
+// [ioseq.PipeThrough], so the compressed body is never held in memory
+// in its entirety.
+//
+// Requests with no body, or that already have a Content-Encoding
+// header, are sent unchanged. Because the length of the compressed
+// body is not known in advance, compressed requests are sent with
+// chunked encoding. If a request's GetBody field is set, the
+// compressed request's GetBody returns a fresh compressed body, so
+// that redirects and retries continue to work.
+//
+// Note that not all servers accept compressed request bodies.
+type CompressTransport struct {
+	// Transport is used to make the requests. If it's
+	// nil, [http.DefaultTransport] is used.
+	Transport http.RoundTripper
+
+	// Encoding holds the content coding to use, such as
+	// "zstd". If it's empty, "gzip" is used.
+	Encoding string
+
+	// NewWriter returns a writer that compresses data
+	// written to it in the given encoding and writes the
+	// result to w. If it's nil, Encoding must be empty or
+	// "gzip", and [gzip.NewWriter] is used.
+	NewWriter func(w io.Writer) io.WriteCloser
+}
+
+// RoundTrip implements [http.RoundTripper.RoundTrip].
+func (t *CompressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+		return transport.RoundTrip(req)
+	}
+	encoding, newWriter := t.Encoding, t.NewWriter
+	if encoding == "" {
+		encoding = "gzip"
+	}
+	if newWriter == nil {
+		if encoding != "gzip" {
+			req.Body.Close()
+			return nil, fmt.Errorf("httpseq: no compressor provided for encoding %q", encoding)
+		}
+		newWriter = func(w io.Writer) io.WriteCloser {
+			return gzip.NewWriter(w)
+		}
+	}
+	compress := func(body io.ReadCloser) io.ReadCloser {
+		return &compressedBody{
+			ReadCloser: ioseq.PipeThrough(body, newWriter, ioseq.DefaultBufferSize),
+			src:        body,
+		}
+	}
+	// A RoundTripper must not modify the request.
+	req1 := req.Clone(req.Context())
+	req1.Header.Set("Content-Encoding", encoding)
+	req1.ContentLength = -1
+	req1.Body = compress(req.Body)
+	if getBody := req.GetBody; getBody != nil {
+		req1.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return compress(body), nil
+		}
+	}
+	return transport.RoundTrip(req1)
+}
+
+// compressedBody is a compressed request body.
+// Closing it closes the uncompressed source too.
+type compressedBody struct {
+	io.ReadCloser
+	src io.Closer
+}
+
+func (b *compressedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if err1 := b.src.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
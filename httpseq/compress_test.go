@@ -0,0 +1,116 @@
+package httpseq
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rogpeppe/ioseq"
+)
+
+// decodingHandler returns a handler that records the decoded
+// body and Content-Encoding of each request it receives.
+func decodingHandler(t *testing.T, got *[]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var r io.Reader = req.Body
+		var err error
+		switch enc := req.Header.Get("Content-Encoding"); enc {
+		case "gzip":
+			r, err = gzip.NewReader(r)
+		case "deflate":
+			r, err = zlib.NewReader(r)
+		case "":
+		default:
+			t.Errorf("unexpected encoding %q", enc)
+		}
+		if err != nil {
+			t.Errorf("cannot decode body: %v", err)
+			return
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Errorf("cannot read body: %v", err)
+		}
+		*got = append(*got, req.URL.Path+" "+req.Header.Get("Content-Encoding")+" "+string(data))
+		if req.URL.Path == "/start" {
+			http.Redirect(w, req, "/end", http.StatusTemporaryRedirect)
+		}
+	})
+}
+
+func TestCompressTransport(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(decodingHandler(t, &got))
+	defer srv.Close()
+	client := &http.Client{
+		Transport: &CompressTransport{
+			Transport: srv.Client().Transport,
+		},
+	}
+	body := strings.Repeat("hello world ", 1000)
+	// The request is redirected, so the body must be
+	// compressed twice via GetBody.
+	req, err := NewRequest(context.Background(), "POST", srv.URL+"/start", ioseq.Restartable(ioseq.SeqFromString(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	want := []string{
+		"/start gzip " + body,
+		"/end gzip " + body,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests (len %d)", len(got))
+	}
+}
+
+func TestCompressTransportCustomEncoding(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(decodingHandler(t, &got))
+	defer srv.Close()
+	client := &http.Client{
+		Transport: &CompressTransport{
+			Transport: srv.Client().Transport,
+			Encoding:  "deflate",
+			NewWriter: func(w io.Writer) io.WriteCloser {
+				return zlib.NewWriter(w)
+			},
+		},
+	}
+	resp, err := client.Post(srv.URL+"/x", "text/plain", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	// Requests without a body are not compressed.
+	resp, err = client.Get(srv.URL + "/y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	want := "/x deflate hello\n/y  "
+	if strings.Join(got, "\n") != want {
+		t.Errorf("unexpected requests; got %q want %q", got, want)
+	}
+}
+
+func TestCompressTransportNoCompressor(t *testing.T) {
+	client := &http.Client{
+		Transport: &CompressTransport{
+			Encoding: "zstd",
+		},
+	}
+	_, err := client.Post("http://example.invalid/", "text/plain", strings.NewReader("hello"))
+	if err == nil || !strings.Contains(err.Error(), `no compressor provided for encoding "zstd"`) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
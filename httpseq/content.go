@@ -0,0 +1,199 @@
+package httpseq
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rogpeppe/ioseq"
+)
+
+// spillMemLimit holds the amount of data that ServeSeqContent
+// holds in memory before spilling to a temporary file.
+const spillMemLimit = 1 << 20
+
+// sniffLen holds the number of bytes read by http.ServeContent
+// to detect the content type.
+const sniffLen = 512
+
+var errBackwardSeek = errors.New("httpseq: cannot seek backwards in single-use sequence")
+
+// ServeSeqContent is like [http.ServeContent] except that the content
+// is read from body. Like ServeContent, it handles conditional
+// requests (If-Match, If-None-Match, If-Modified-Since and so on, using
+// modtime and any ETag header already set on w), range requests and
+// If-Range, and sets the Content-Type from name or the data if it is
+// not already set.
+//
+// The data is streamed from body where possible. It is only
+// read into memory, or into a temporary file if there is more than
+// 1MiB, when that is unavoidable: when the request asks for more
+// than one range, or when the size of body is unknown and the response
+// needs it (which is not the case for a response to a conditional
+// request that results in "304 Not Modified"). If body is an
+// [ioseq.RestartableSeq], it is iterated over again instead.
+func ServeSeqContent[B Body](w http.ResponseWriter, req *http.Request, name string, modtime time.Time, body B) {
+	seq, size := seqAndSize(body)
+	_, restartable := any(body).(ioseq.RestartableSeq)
+	s := &lazySeeker{
+		seq:         seq,
+		size:        size,
+		restartable: restartable,
+		// A request for multiple ranges can ask for the ranges in
+		// any order, so we need random access to the data.
+		needSpill: strings.Contains(req.Header.Get("Range"), ",") && !restartable,
+	}
+	defer s.Close()
+	http.ServeContent(w, req, name, modtime, s)
+}
+
+// lazySeeker implements io.ReadSeeker on a sequence without
+// reading any data until it's needed, and streaming it where
+// possible.
+type lazySeeker struct {
+	seq         ioseq.Seq
+	restartable bool
+	needSpill   bool
+
+	// size holds the size of the data, or -1 if unknown.
+	size int64
+
+	// pos holds the current position.
+	pos int64
+
+	// r holds the reader for the current iteration, positioned
+	// at rpos.
+	r    io.ReadCloser
+	rpos int64
+
+	// head holds up to the first sniffLen bytes of the data when
+	// they have been read by the current iteration, so that the
+	// reader can seek back over them after the content type has
+	// been sniffed.
+	head []byte
+
+	// spilled holds all the data when it has been necessary
+	// to read it all.
+	spilled io.ReadSeekCloser
+}
+
+func (s *lazySeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.pos
+	case io.SeekEnd:
+		if s.size < 0 {
+			if err := s.findSize(); err != nil {
+				return s.pos, err
+			}
+		}
+		offset += s.size
+	default:
+		return s.pos, errors.New("httpseq: invalid whence")
+	}
+	if offset < 0 {
+		return s.pos, errors.New("httpseq: seek to negative position")
+	}
+	s.pos = offset
+	return offset, nil
+}
+
+// findSize finds the size of the data, spilling it if necessary.
+func (s *lazySeeker) findSize() error {
+	if s.restartable {
+		size, err := ioseq.DiscardSeq(s.seq)
+		if err != nil {
+			return err
+		}
+		s.size = size
+		return nil
+	}
+	return s.spill()
+}
+
+// spill reads all the data so that it can be accessed randomly.
+func (s *lazySeeker) spill() error {
+	if s.spilled != nil {
+		return nil
+	}
+	seq := s.seq
+	if s.r != nil {
+		// Only the head can have been read so far (when sniffing
+		// the content type) without the size being known, so
+		// carry on from where the current iteration left off.
+		if s.rpos > int64(len(s.head)) {
+			return errBackwardSeek
+		}
+		seq = ioseq.ConcatSeqs(ioseq.SeqFromBytes(s.head), ioseq.SeqFromReader(s.r, ioseq.DefaultBufferSize))
+	}
+	spilled, err := ioseq.SeekableFromSeq(seq, spillMemLimit)
+	if err != nil {
+		return err
+	}
+	s.spilled = spilled
+	s.size, err = spilled.Seek(0, io.SeekEnd)
+	return err
+}
+
+func (s *lazySeeker) Read(buf []byte) (int, error) {
+	if s.needSpill {
+		if err := s.spill(); err != nil {
+			return 0, err
+		}
+	}
+	if s.spilled != nil {
+		if _, err := s.spilled.Seek(s.pos, io.SeekStart); err != nil {
+			return 0, err
+		}
+		n, err := s.spilled.Read(buf)
+		s.pos += int64(n)
+		return n, err
+	}
+	if s.r != nil && s.pos < s.rpos {
+		if s.rpos <= int64(len(s.head)) {
+			n := copy(buf, s.head[s.pos:s.rpos])
+			s.pos += int64(n)
+			return n, nil
+		}
+		if !s.restartable {
+			return 0, errBackwardSeek
+		}
+		s.r.Close()
+		s.r = nil
+	}
+	if s.r == nil {
+		s.r = ioseq.ReaderFromSeq(ioseq.SkipSeq(s.seq, s.pos))
+		s.rpos = s.pos
+		s.head = nil
+	}
+	if s.pos > s.rpos {
+		n, err := io.CopyN(io.Discard, s.r, s.pos-s.rpos)
+		s.rpos += n
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := s.r.Read(buf)
+	if s.rpos == int64(len(s.head)) && s.rpos < sniffLen {
+		s.head = append(s.head, buf[:min(n, sniffLen-len(s.head))]...)
+	}
+	s.rpos += int64(n)
+	s.pos += int64(n)
+	return n, err
+}
+
+func (s *lazySeeker) Close() error {
+	var err error
+	if s.r != nil {
+		err = s.r.Close()
+	}
+	if s.spilled != nil {
+		if err1 := s.spilled.Close(); err == nil {
+			err = err1
+		}
+	}
+	return err
+}
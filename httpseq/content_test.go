@@ -0,0 +1,199 @@
+package httpseq
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rogpeppe/ioseq"
+)
+
+var contentModTime = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// countingSeq returns a sequence that yields data in small chunks,
+// incrementing *n each time it is iterated over.
+func countingSeq(data string, n *int) ioseq.Seq {
+	return func(yield func([]byte, error) bool) {
+		*n++
+		for chunk, err := range ioseq.SeqFromReader(strings.NewReader(data), 3) {
+			if !yield(chunk, err) {
+				return
+			}
+		}
+	}
+}
+
+func serveContent[B Body](header http.Header, body B) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	ServeSeqContent(rec, req, "hello.txt", contentModTime, body)
+	return rec
+}
+
+func TestServeSeqContentFull(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		body func(n *int) any
+	}{{
+		name: "Unsized",
+		body: func(n *int) any { return countingSeq("hello, world", n) },
+	}, {
+		name: "Sized",
+		body: func(n *int) any { return ioseq.WithSize(countingSeq("hello, world", n), 12) },
+	}, {
+		name: "Restartable",
+		body: func(n *int) any { return ioseq.Restartable(countingSeq("hello, world", n)) },
+	}} {
+		t.Run(test.name, func(t *testing.T) {
+			var n int
+			var rec *httptest.ResponseRecorder
+			switch body := test.body(&n).(type) {
+			case ioseq.Seq:
+				rec = serveContent(nil, body)
+			case ioseq.SizedSeq:
+				rec = serveContent(nil, body)
+			case ioseq.RestartableSeq:
+				rec = serveContent(nil, body)
+			}
+			if rec.Code != http.StatusOK {
+				t.Fatalf("unexpected status %d", rec.Code)
+			}
+			if got := rec.Body.String(); got != "hello, world" {
+				t.Errorf("unexpected body %q", got)
+			}
+			if got := rec.Header().Get("Content-Length"); got != "12" {
+				t.Errorf("unexpected Content-Length %q", got)
+			}
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain") {
+				t.Errorf("unexpected Content-Type %q", got)
+			}
+		})
+	}
+}
+
+func TestServeSeqContentSniff(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Range", "bytes=2-")
+	data := "<html><body>" + strings.Repeat("x", 1000) + "</body></html>"
+	ServeSeqContent(rec, req, "noext", contentModTime, ioseq.WithSize(ioseq.OnceSeq(ioseq.SeqFromString(data)), int64(len(data))))
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+		t.Errorf("unexpected Content-Type %q", got)
+	}
+	if got := rec.Body.String(); got != data[2:] {
+		t.Errorf("unexpected body %q", got)
+	}
+}
+
+func TestServeSeqContentSingleRange(t *testing.T) {
+	var n int
+	rec := serveContent(http.Header{
+		"Range": {"bytes=7-10"},
+	}, ioseq.WithSize(ioseq.OnceSeq(countingSeq("hello, world", &n)), 12))
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+	if got := rec.Body.String(); got != "worl" {
+		t.Errorf("unexpected body %q", got)
+	}
+	if got := rec.Header().Get("Content-Range"); got != "bytes 7-10/12" {
+		t.Errorf("unexpected Content-Range %q", got)
+	}
+}
+
+func TestServeSeqContentMultipleRanges(t *testing.T) {
+	for _, restartable := range []bool{false, true} {
+		var n int
+		header := http.Header{
+			"Range": {"bytes=7-10,0-4"},
+		}
+		var rec *httptest.ResponseRecorder
+		if restartable {
+			rec = serveContent(header, ioseq.Restartable(countingSeq("hello, world", &n)))
+		} else {
+			rec = serveContent(header, ioseq.OnceSeq(countingSeq("hello, world", &n)))
+		}
+		if rec.Code != http.StatusPartialContent {
+			t.Fatalf("unexpected status %d", rec.Code)
+		}
+		mediaType, params, err := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+		if err != nil || mediaType != "multipart/byteranges" {
+			t.Fatalf("unexpected Content-Type %q", rec.Header().Get("Content-Type"))
+		}
+		r := multipart.NewReader(rec.Body, params["boundary"])
+		var parts []string
+		for {
+			p, err := r.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parts = append(parts, string(data))
+		}
+		if len(parts) != 2 || parts[0] != "worl" || parts[1] != "hello" {
+			t.Errorf("unexpected parts %q", parts)
+		}
+	}
+}
+
+func TestServeSeqContentNotModified(t *testing.T) {
+	var n int
+	rec := serveContent(http.Header{
+		"If-Modified-Since": {contentModTime.Format(http.TimeFormat)},
+	}, countingSeq("hello, world", &n))
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+	if n != 0 {
+		t.Errorf("sequence iterated %d times; want 0", n)
+	}
+}
+
+func TestServeSeqContentError(t *testing.T) {
+	rec := serveContent(nil, ioseq.OnceSeq(ioseq.ConcatSeqs(
+		ioseq.SeqFromString("hello"),
+		func(yield func([]byte, error) bool) {
+			yield(nil, io.ErrUnexpectedEOF)
+		},
+	)))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+}
+
+func TestServeSeqContentSniffUnsized(t *testing.T) {
+	var n int
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	data := "<html><body>" + strings.Repeat("x", 1000) + "</body></html>"
+	ServeSeqContent(rec, req, "noext", contentModTime, ioseq.OnceSeq(countingSeq(data, &n)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+		t.Errorf("unexpected Content-Type %q", got)
+	}
+	if got := rec.Body.String(); got != data {
+		t.Errorf("unexpected body %q", got)
+	}
+	if n != 1 {
+		t.Errorf("sequence iterated %d times; want 1", n)
+	}
+}
@@ -0,0 +1,98 @@
+package httpseq
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// StatusError is the error returned for a response with an
+// unsuccessful status. Its StatusCode method makes it retryable by
+// [ioseq.DefaultErrorClassifier] when the status is 5xx or 429, and
+// causes [ServeSeq] to respond with the same status when it is
+// encountered before any data has been written.
+type StatusError struct {
+	// Code holds the HTTP status code, such as 503.
+	Code int
+
+	// Status holds the status line, such as "503 Service Unavailable".
+	Status string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected HTTP response status %s", e.Status)
+}
+
+// StatusCode returns e.Code.
+func (e *StatusError) StatusCode() int {
+	return e.Code
+}
+
+// CheckStatus returns a [*StatusError] if resp does not have a 2xx
+// status, or nil otherwise.
+func CheckStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return &StatusError{
+		Code:   resp.StatusCode,
+		Status: resp.Status,
+	}
+}
+
+// RangeOpener returns a function suitable for passing to
+// [ioseq.RetrySeq] that fetches url with a GET request, using a Range
+// header to start at the requested offset. If client is nil,
+// [http.DefaultClient] is used.
+//
+// An unsuccessful response results in a [*StatusError], so that
+// [ioseq.RetrySeq], which uses [ioseq.DefaultErrorClassifier] by
+// default, retries server errors and rate limiting but not other
+// failures. To stop waiting between retries when ctx is cancelled,
+// use [ioseq.RetrySeqContext] with the same context.
+//
+// If the server ignores the Range header, the data before the offset
+// is discarded; if it responds that the range is not satisfiable,
+// there is taken to be no more data. The content is requested without
+// any content coding so that offsets refer to the bytes that are
+// yielded.
+func RangeOpener(ctx context.Context, client *http.Client, url string) func(offset int64) (io.ReadCloser, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(offset int64) (io.ReadCloser, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept-Encoding", "identity")
+		if offset > 0 {
+			req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if offset > 0 && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			// There's no data after offset.
+			resp.Body.Close()
+			return http.NoBody, nil
+		}
+		if err := CheckStatus(resp); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		if offset > 0 && resp.StatusCode != http.StatusPartialContent {
+			if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+				resp.Body.Close()
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return nil, err
+			}
+		}
+		return resp.Body, nil
+	}
+}
@@ -0,0 +1,85 @@
+package httpseq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rogpeppe/ioseq"
+)
+
+func TestRangeOpenerRetry(t *testing.T) {
+	const content = "hello world"
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ranges = append(ranges, req.Header.Get("Range"))
+		switch len(ranges) {
+		case 1:
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+		case 2:
+			// Send some of the data, then drop the connection.
+			w.Header().Set("Content-Length", "11")
+			w.Write([]byte(content[:5]))
+			http.NewResponseController(w).Flush()
+			panic(http.ErrAbortHandler)
+		default:
+			http.ServeContent(w, req, "", time.Time{}, strings.NewReader(content))
+		}
+	}))
+	defer srv.Close()
+	seq := ioseq.RetrySeq(RangeOpener(context.Background(), srv.Client(), srv.URL), ioseq.RetryPolicy{
+		MaxRetries: 3,
+		Classifier: ioseq.DefaultErrorClassifier,
+	})
+	data, err := ioseq.ReadAllSeq(seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Errorf("unexpected data %q", data)
+	}
+	if want := []string{"", "", "bytes=5-"}; strings.Join(ranges, ",") != strings.Join(want, ",") {
+		t.Errorf("unexpected ranges; got %q want %q", ranges, want)
+	}
+}
+
+func TestRangeOpenerFatalStatus(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		http.NotFound(w, req)
+	}))
+	defer srv.Close()
+	seq := ioseq.RetrySeq(RangeOpener(context.Background(), srv.Client(), srv.URL), ioseq.RetryPolicy{
+		MaxRetries: 3,
+		Classifier: ioseq.DefaultErrorClassifier,
+	})
+	_, err := ioseq.ReadAllSeq(seq)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("unexpected request count %d", requests)
+	}
+}
+
+func TestRangeOpenerIgnoredRange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+	defer srv.Close()
+	r, err := RangeOpener(context.Background(), nil, srv.URL)(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := ioseq.ReadAllSeq(ioseq.SeqFromReader(r, ioseq.DefaultBufferSize))
+	if err != nil || string(data) != "world" {
+		t.Errorf("unexpected result %q, %v", data, err)
+	}
+}
@@ -0,0 +1,85 @@
+// Package httpseq provides helpers for using [ioseq.Seq] values
+// with the net/http package.
+package httpseq
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/rogpeppe/ioseq"
+)
+
+// Body is the set of types that can be used as a request body by
+// [NewRequest] and [PostSeq]. A plain [ioseq.Seq] is read once, with
+// unknown length; an [ioseq.SizedSeq] also provides the length; and an
+// [ioseq.RestartableSeq] can be read more than once, and may provide
+// the length. An [ioseq.ReaderSeq] is read directly from its reader,
+// so that copying a file to the network can avoid user-space buffers;
+// its length is known when [ioseq.ReaderSeq.Size] can determine it.
+//
+// A function literal must be converted to [ioseq.Seq] before it
+// can be used as a Body.
+type Body interface {
+	ioseq.Seq | ioseq.SizedSeq | ioseq.RestartableSeq | ioseq.ReaderSeq
+}
+
+// NewRequest is like [http.NewRequestWithContext] except that the
+// request body is read from body.
+//
+// If the size of body is known, the request's ContentLength is set
+// from it, and reading the body fails if the sequence does not hold
+// exactly that many bytes (see [ioseq.ExpectSize]); otherwise the
+// body is sent with chunked encoding. If body is an
+// [ioseq.RestartableSeq], the request's GetBody field is set so that
+// the client can resend the body when following redirects or
+// retrying.
+func NewRequest[B Body](ctx context.Context, method, url string, body B) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	seq, size := seqAndSize(body)
+	_, restartable := any(body).(ioseq.RestartableSeq)
+	rs, isReaderSeq := any(body).(ioseq.ReaderSeq)
+	getBody := func() (io.ReadCloser, error) {
+		switch {
+		case size == 0:
+			return http.NoBody, nil
+		case isReaderSeq:
+			// The transport sees through NopCloser, so it
+			// can still use the reader's zero-copy paths.
+			return io.NopCloser(rs.Reader), nil
+		case size > 0:
+			return ioseq.ReaderFromSizedSeq(ioseq.WithSize(ioseq.ExpectSize(seq, size), size)), nil
+		default:
+			return ioseq.ReaderFromSeq(seq), nil
+		}
+	}
+	req.Body, _ = getBody()
+	if restartable {
+		req.GetBody = getBody
+	}
+	// A negative size causes the body to be sent
+	// with chunked encoding.
+	req.ContentLength = size
+	return req, nil
+}
+
+// PostSeq is like [http.Client.Post] except that the request body is
+// read from body, as described in [NewRequest]. If client is nil,
+// [http.DefaultClient] is used.
+//
+// As with [http.Client.Do], the body is closed even if
+// an error is returned.
+func PostSeq[B Body](client *http.Client, url, contentType string, body B) (*http.Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := NewRequest(context.Background(), "POST", url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return client.Do(req)
+}
@@ -0,0 +1,172 @@
+package httpseq
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rogpeppe/ioseq"
+)
+
+func TestNewRequestFollowsRedirect(t *testing.T) {
+	type result struct {
+		path          string
+		body          string
+		contentLength int64
+	}
+	var results []result
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Errorf("cannot read body: %v", err)
+		}
+		results = append(results, result{req.URL.Path, string(data), req.ContentLength})
+		if req.URL.Path == "/start" {
+			http.Redirect(w, req, "/end", http.StatusTemporaryRedirect)
+		}
+	}))
+	defer srv.Close()
+
+	body := ioseq.Restartable(ioseq.SeqFromString("hello world"))
+	body.Size = 11
+	req, err := NewRequest(context.Background(), "POST", srv.URL+"/start", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %v", resp.Status)
+	}
+	want := []result{
+		{"/start", "hello world", 11},
+		{"/end", "hello world", 11},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("unexpected results; got %v want %v", results, want)
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("unexpected result %d; got %v want %v", i, results[i], want[i])
+		}
+	}
+}
+
+func TestNewRequestUnknownSize(t *testing.T) {
+	req, err := NewRequest(context.Background(), "PUT", "http://example.com/", ioseq.Restartable(ioseq.SeqFromString("x")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer req.Body.Close()
+	if req.ContentLength != -1 {
+		t.Errorf("unexpected content length %d", req.ContentLength)
+	}
+	if req.GetBody == nil {
+		t.Errorf("GetBody not set")
+	}
+}
+
+func TestPostSeq(t *testing.T) {
+	type result struct {
+		body          string
+		contentLength int64
+		contentType   string
+	}
+	var got result
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		got = result{string(data), req.ContentLength, req.Header.Get("Content-Type")}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		testName string
+		post     func() (*http.Response, error)
+		want     result
+	}{{
+		testName: "Seq",
+		post: func() (*http.Response, error) {
+			return PostSeq(nil, srv.URL, "text/plain", ioseq.SeqFromString("hello"))
+		},
+		want: result{"hello", -1, "text/plain"},
+	}, {
+		testName: "SizedSeq",
+		post: func() (*http.Response, error) {
+			return PostSeq(srv.Client(), srv.URL, "text/plain", ioseq.WithSize(ioseq.SeqFromString("hello"), 5))
+		},
+		want: result{"hello", 5, "text/plain"},
+	}, {
+		testName: "RestartableSeq",
+		post: func() (*http.Response, error) {
+			return PostSeq(srv.Client(), srv.URL, "text/plain", ioseq.Restartable(ioseq.SeqFromString("hello")))
+		},
+		want: result{"hello", -1, "text/plain"},
+	}}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			got = result{}
+			resp, err := test.post()
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("unexpected status %v", resp.Status)
+			}
+			if got != test.want {
+				t.Errorf("unexpected result; got %+v want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewRequestWrongSize(t *testing.T) {
+	req, err := NewRequest(context.Background(), "PUT", "http://example.com/", ioseq.WithSize(ioseq.SeqFromString("hello"), 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer req.Body.Close()
+	if req.GetBody != nil {
+		t.Errorf("GetBody unexpectedly set for non-restartable body")
+	}
+	if _, err := io.ReadAll(req.Body); err != io.ErrUnexpectedEOF {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestNewRequestReaderSeq(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, []byte("hello world"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	req, err := NewRequest(context.Background(), "POST", "http://example.com", ioseq.ReaderSeq{Reader: f})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.ContentLength != 11 {
+		t.Errorf("unexpected ContentLength %d", req.ContentLength)
+	}
+	if req.GetBody != nil {
+		t.Errorf("GetBody unexpectedly set")
+	}
+	data, err := io.ReadAll(req.Body)
+	if err != nil || string(data) != "hello world" {
+		t.Errorf("unexpected body %q, %v", data, err)
+	}
+}
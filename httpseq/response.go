@@ -0,0 +1,119 @@
+package httpseq
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/rogpeppe/ioseq"
+)
+
+// encodingFormats maps content codings to the names of
+// formats registered with [ioseq.RegisterDecompressor].
+var encodingFormats = map[string]string{
+	"gzip":    "gzip",
+	"x-gzip":  "gzip",
+	"deflate": "zlib",
+}
+
+// lookupDecompressor is used to find the decompressor for a content
+// coding. It's a variable so that tests can add codings without
+// changing the global registry.
+var lookupDecompressor = ioseq.LookupDecompressor
+
+// ResponseSeq returns a [ioseq.Seq] that yields the body of resp,
+// decoded according to its Content-Encoding header. The body is closed
+// when the iteration finishes. The returned sequence must be iterated
+// over exactly once.
+//
+// The gzip and deflate codings are supported by default. Other codings
+// are looked up by name in the registry used by [ioseq.DecompressSeq],
+// so support for a coding such as "zstd" can be added by calling
+// [ioseq.RegisterDecompressor] with that name. If the body uses a
+// coding that is not supported, the sequence yields an error without
+// reading the body.
+//
+// Because the whole body must be decoded, a compressed stream that is
+// truncated results in an error (typically [io.ErrUnexpectedEOF]), as
+// does any data following the end of the compressed stream that the
+// decoder does not itself consume.
+//
+// If the transport has already decoded the body, as indicated by
+// resp.Uncompressed, it is yielded unchanged.
+func ResponseSeq(resp *http.Response) ioseq.Seq {
+	return func(yield func([]byte, error) bool) {
+		defer resp.Body.Close()
+		seq := ioseq.SeqFromReader(resp.Body, ioseq.DefaultBufferSize)
+		if !resp.Uncompressed {
+			// Codings are listed in the order they were
+			// applied, so undo them in reverse order.
+			codings := strings.Split(resp.Header.Get("Content-Encoding"), ",")
+			for i := len(codings) - 1; i >= 0; i-- {
+				coding := strings.ToLower(strings.TrimSpace(codings[i]))
+				if coding == "" || coding == "identity" {
+					continue
+				}
+				format := coding
+				if f, ok := encodingFormats[coding]; ok {
+					format = f
+				}
+				newReader, ok := lookupDecompressor(format)
+				if !ok {
+					yield(nil, fmt.Errorf("httpseq: unsupported content encoding %q", coding))
+					return
+				}
+				seq = ioseq.PipeSeqThroughReader(seq, checkTrailing(coding, newReader))
+			}
+		}
+		seq(yield)
+	}
+}
+
+// checkTrailing returns a function that calls newReader and wraps the
+// resulting reader so that it returns an error if there is any data
+// left in its source when it reaches the end of the decoded data.
+func checkTrailing(coding string, newReader func(io.Reader) (io.Reader, error)) func(io.Reader) (io.Reader, error) {
+	return func(r io.Reader) (io.Reader, error) {
+		dr, err := newReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return &trailingReader{
+			coding: coding,
+			r:      dr,
+			src:    r,
+		}, nil
+	}
+}
+
+// trailingReader reads from r, checking that src has been
+// exhausted when r returns io.EOF.
+type trailingReader struct {
+	coding string
+	r      io.Reader
+	src    io.Reader
+}
+
+func (r *trailingReader) Read(buf []byte) (int, error) {
+	n, err := r.r.Read(buf)
+	if err != io.EOF {
+		return n, err
+	}
+	var b [1]byte
+	switch _, serr := io.ReadFull(r.src, b[:]); serr {
+	case io.EOF:
+		return n, io.EOF
+	case nil:
+		return n, fmt.Errorf("httpseq: data after end of %s stream", r.coding)
+	default:
+		return n, serr
+	}
+}
+
+func (r *trailingReader) Close() error {
+	if c, ok := r.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
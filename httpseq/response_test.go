@@ -0,0 +1,144 @@
+package httpseq
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rogpeppe/ioseq"
+)
+
+func compress(t *testing.T, newWriter func(io.Writer) io.WriteCloser, s string) string {
+	var buf bytes.Buffer
+	w := newWriter(&buf)
+	if _, err := io.WriteString(w, s); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func gzipString(t *testing.T, s string) string {
+	return compress(t, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }, s)
+}
+
+func zlibString(t *testing.T, s string) string {
+	return compress(t, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }, s)
+}
+
+func TestResponseSeq(t *testing.T) {
+	hello := "hello world"
+	gz := gzipString(t, hello)
+	tests := []struct {
+		testName string
+		encoding string
+		body     string
+		want     string
+		wantErr  string
+	}{{
+		testName: "Identity",
+		body:     hello,
+		want:     hello,
+	}, {
+		testName: "Gzip",
+		encoding: "gzip",
+		body:     gz,
+		want:     hello,
+	}, {
+		testName: "Deflate",
+		encoding: "deflate",
+		body:     zlibString(t, hello),
+		want:     hello,
+	}, {
+		testName: "Multiple",
+		encoding: "deflate, gzip",
+		body:     gzipString(t, zlibString(t, hello)),
+		want:     hello,
+	}, {
+		testName: "Truncated",
+		encoding: "gzip",
+		body:     gz[:len(gz)-4],
+		want:     hello,
+		wantErr:  "unexpected EOF",
+	}, {
+		testName: "TrailingGarbage",
+		encoding: "gzip",
+		body:     gz + "this is not gzip data",
+		want:     hello,
+		wantErr:  "gzip: invalid header",
+	}, {
+		testName: "DeflateTrailingGarbage",
+		encoding: "deflate",
+		body:     zlibString(t, hello) + "GARBAGE",
+		want:     hello,
+		wantErr:  "httpseq: data after end of deflate stream",
+	}, {
+		testName: "Unsupported",
+		encoding: "br",
+		body:     "xxx",
+		wantErr:  `httpseq: unsupported content encoding "br"`,
+	}}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if test.encoding != "" {
+					w.Header().Set("Content-Encoding", test.encoding)
+				}
+				io.WriteString(w, test.body)
+			}))
+			defer srv.Close()
+			// Disable compression so that the transport
+			// doesn't decode gzip bodies itself.
+			client := &http.Client{
+				Transport: &http.Transport{DisableCompression: true},
+			}
+			resp, err := client.Get(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := ioseq.ReadAllSeq(ResponseSeq(resp))
+			if string(data) != test.want {
+				t.Errorf("unexpected data; got %q want %q", data, test.want)
+			}
+			if test.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+			} else if err == nil || err.Error() != test.wantErr {
+				t.Errorf("unexpected error; got %v want %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestResponseSeqRegistered(t *testing.T) {
+	// Add a trivial "coding" that upper-cases its input.
+	oldLookup := lookupDecompressor
+	t.Cleanup(func() {
+		lookupDecompressor = oldLookup
+	})
+	lookupDecompressor = func(name string) (func(io.Reader) (io.Reader, error), bool) {
+		if name != "x-upper" {
+			return oldLookup(name)
+		}
+		return func(r io.Reader) (io.Reader, error) {
+			data, err := io.ReadAll(r)
+			return strings.NewReader(strings.ToUpper(string(data))), err
+		}, true
+	}
+	resp := &http.Response{
+		Header: http.Header{"Content-Encoding": {"x-upper"}},
+		Body:   io.NopCloser(strings.NewReader("hello")),
+	}
+	data, err := ioseq.ReadAllSeq(ResponseSeq(resp))
+	if string(data) != "HELLO" || err != nil {
+		t.Errorf("unexpected result %q, %v", data, err)
+	}
+}
@@ -0,0 +1,61 @@
+package httpseq
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rogpeppe/ioseq"
+)
+
+// ResumableBody is the set of types accepted by [ServeResumable]:
+// data that can be read again from any point, either by iterating
+// over it again or by reading at an offset.
+type ResumableBody interface {
+	ioseq.RestartableSeq | *io.SectionReader
+}
+
+// ServeResumable serves body in response to req so that clients can
+// resume an interrupted download with a range request. It sets the
+// ETag header to etag, which must be a strong validator: it must change
+// whenever the content changes, so that a client resuming with an
+// If-Range header never receives a mixture of old and new content.
+// The etag may be given with or without the surrounding double
+// quotes; ServeResumable panics if it's a weak validator (starting with
+// "W/").
+//
+// Range requests are answered with 206 (Partial Content), with a
+// multipart/byteranges body when several ranges are requested. When the
+// If-Range header does not match etag or modtime, the whole content is
+// served. Other conditional headers are handled as for
+// [http.ServeContent].
+//
+// When body is an [ioseq.RestartableSeq], each range is produced by
+// iterating over the sequence again and discarding the data before the
+// start of the range, without buffering any of it. Its Size field
+// should be set if possible, as otherwise the sequence must be iterated
+// over an extra time to find it. When body is an [io.SectionReader],
+// each range is read directly at its offset; body's current offset is
+// not used or changed.
+func ServeResumable[B ResumableBody](w http.ResponseWriter, req *http.Request, name string, modtime time.Time, etag string, body B) {
+	w.Header().Set("ETag", strongETag(etag))
+	switch body := any(body).(type) {
+	case ioseq.RestartableSeq:
+		ServeSeqContent(w, req, name, modtime, body)
+	case *io.SectionReader:
+		http.ServeContent(w, req, name, modtime, io.NewSectionReader(body, 0, body.Size()))
+	}
+}
+
+// strongETag returns etag in quoted form, panicking if it's not a
+// strong validator.
+func strongETag(etag string) string {
+	if strings.HasPrefix(etag, "W/") {
+		panic("httpseq: ServeResumable requires a strong validator")
+	}
+	if len(etag) >= 2 && strings.HasPrefix(etag, `"`) && strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
@@ -0,0 +1,117 @@
+package httpseq
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rogpeppe/ioseq"
+)
+
+func serveResumable[B ResumableBody](header http.Header, etag string, body B) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	ServeResumable(rec, req, "artifact.bin", contentModTime, etag, body)
+	return rec
+}
+
+func TestServeResumableIfRange(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		ifRange  string
+		wantCode int
+		wantBody string
+	}{{
+		name:     "Match",
+		ifRange:  `"v1"`,
+		wantCode: http.StatusPartialContent,
+		wantBody: "world",
+	}, {
+		name:     "Mismatch",
+		ifRange:  `"v0"`,
+		wantCode: http.StatusOK,
+		wantBody: "hello, world",
+	}, {
+		name:     "Weak",
+		ifRange:  `W/"v1"`,
+		wantCode: http.StatusOK,
+		wantBody: "hello, world",
+	}} {
+		t.Run(test.name, func(t *testing.T) {
+			var n int
+			seq := ioseq.Restartable(countingSeq("hello, world", &n))
+			seq.Size = 12
+			rec := serveResumable(http.Header{
+				"Range":    {"bytes=7-"},
+				"If-Range": {test.ifRange},
+			}, "v1", seq)
+			if rec.Code != test.wantCode {
+				t.Fatalf("unexpected status %d", rec.Code)
+			}
+			if got := rec.Body.String(); got != test.wantBody {
+				t.Errorf("unexpected body %q", got)
+			}
+			if got := rec.Header().Get("ETag"); got != `"v1"` {
+				t.Errorf("unexpected ETag %q", got)
+			}
+			if n != 1 {
+				t.Errorf("sequence iterated %d times; want 1", n)
+			}
+		})
+	}
+}
+
+func TestServeResumableMultipleRanges(t *testing.T) {
+	var n int
+	seq := ioseq.Restartable(countingSeq("hello, world", &n))
+	seq.Size = 12
+	for _, serve := range []func(http.Header) *httptest.ResponseRecorder{
+		func(h http.Header) *httptest.ResponseRecorder {
+			return serveResumable(h, `"v1"`, seq)
+		},
+		func(h http.Header) *httptest.ResponseRecorder {
+			return serveResumable(h, `"v1"`, io.NewSectionReader(strings.NewReader("hello, world"), 0, 12))
+		},
+	} {
+		rec := serve(http.Header{
+			"Range": {"bytes=7-10,0-4"},
+		})
+		if rec.Code != http.StatusPartialContent {
+			t.Fatalf("unexpected status %d", rec.Code)
+		}
+		body := rec.Body.String()
+		if !strings.Contains(body, "\r\n\r\nworl\r\n") || !strings.Contains(body, "\r\n\r\nhello\r\n") {
+			t.Errorf("unexpected body %q", body)
+		}
+	}
+}
+
+func TestServeResumableSectionReaderOffset(t *testing.T) {
+	r := io.NewSectionReader(strings.NewReader("xxhello, world"), 2, 12)
+	if _, err := r.Seek(5, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	rec := serveResumable(http.Header{
+		"Range": {"bytes=0-4"},
+	}, "v1", r)
+	if got := rec.Body.String(); got != "hello" {
+		t.Errorf("unexpected body %q", got)
+	}
+	if pos, _ := r.Seek(0, io.SeekCurrent); pos != 5 {
+		t.Errorf("offset changed to %d", pos)
+	}
+}
+
+func TestServeResumableWeakETag(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("no panic for weak validator")
+		}
+	}()
+	serveResumable(nil, `W/"v1"`, ioseq.Restartable(ioseq.SeqFromString("hello")))
+}
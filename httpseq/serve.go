@@ -0,0 +1,135 @@
+package httpseq
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/rogpeppe/ioseq"
+)
+
+// ServeOptions holds options for [ServeSeq].
+type ServeOptions struct {
+	// Flush causes the response to be flushed after each chunk
+	// is written, so that the client sees data as soon as it is
+	// produced.
+	Flush bool
+}
+
+// ServeSeq writes the data from body as the response to req, and
+// returns any error encountered. If the size of body is known, the
+// Content-Length header is set from it (unless it has already been
+// set), and it is an error for the sequence to hold a different amount
+// of data. The iteration is stopped if the request's context is
+// cancelled. If opts is nil, default options are used.
+//
+// If the sequence fails before any data has been written, the response
+// status is set from the error: if it has a StatusCode method (see
+// [ioseq.ErrorClassifier]), that status is used; otherwise it's
+// [http.StatusInternalServerError]. The error text is not sent to the
+// client.
+//
+// If the sequence fails after data has been written, it's too late to
+// change the status, and the client must not mistake the truncated
+// body for a complete one, so ServeSeq aborts the response by
+// panicking with [http.ErrAbortHandler], as [httputil.ReverseProxy]
+// does. The error is not returned in that case, so it should be
+// logged by the producer if needed. Errors from writing the response,
+// and cancellation of the request context, are returned without
+// aborting.
+//
+// If body is an [ioseq.ReaderSeq] and opts.Flush is false, its reader
+// is copied to w with [io.Copy], so that a file can be sent with
+// sendfile(2). In that case, an error reading the body cannot be told
+// apart from an error writing the response, so any error after data
+// has been written aborts the response, and the copy is only stopped
+// by cancellation when a write fails.
+func ServeSeq[B Body](w http.ResponseWriter, req *http.Request, body B, opts *ServeOptions) error {
+	if opts == nil {
+		opts = &ServeOptions{}
+	}
+	seq, size := seqAndSize(body)
+	if size >= 0 {
+		seq = ioseq.ExpectSize(seq, size)
+		if w.Header().Get("Content-Length") == "" {
+			w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		}
+	}
+	if rs, ok := any(body).(ioseq.ReaderSeq); ok && !opts.Flush {
+		return serveReader(w, rs.Reader, size)
+	}
+	ctx := req.Context()
+	written := false
+	for data, err := range ioseq.SeqWithContext(ctx, seq) {
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+				return err
+			}
+			if written {
+				panic(http.ErrAbortHandler)
+			}
+			serveError(w, err)
+			return err
+		}
+		written = true
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		if opts.Flush {
+			if err := http.NewResponseController(w).Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// serveReader is the implementation of [ServeSeq] for an
+// [ioseq.ReaderSeq] holding r.
+func serveReader(w http.ResponseWriter, r io.Reader, size int64) error {
+	if size >= 0 {
+		// The sendfile path in net understands LimitedReader.
+		r = &io.LimitedReader{R: r, N: size}
+	}
+	n, err := io.Copy(w, r)
+	if err == nil && size >= 0 && n < size {
+		err = io.ErrUnexpectedEOF
+	}
+	if err == nil {
+		return nil
+	}
+	if n > 0 {
+		panic(http.ErrAbortHandler)
+	}
+	serveError(w, err)
+	return err
+}
+
+// serveError responds to the request with an error status derived
+// from err, as described in [ServeSeq].
+func serveError(w http.ResponseWriter, err error) {
+	w.Header().Del("Content-Length")
+	code := http.StatusInternalServerError
+	var statusErr interface{ StatusCode() int }
+	if errors.As(err, &statusErr) {
+		code = statusErr.StatusCode()
+	}
+	http.Error(w, http.StatusText(code), code)
+}
+
+// seqAndSize returns the sequence held in body and its size,
+// or -1 if that is not known.
+func seqAndSize[B Body](body B) (ioseq.Seq, int64) {
+	switch body := any(body).(type) {
+	case ioseq.SizedSeq:
+		return body.Seq, body.Size
+	case ioseq.RestartableSeq:
+		return body.Seq, body.Size
+	case ioseq.ReaderSeq:
+		return body.Seq(), body.Size()
+	case ioseq.Seq:
+		return body, -1
+	}
+	panic("unreachable")
+}
@@ -0,0 +1,161 @@
+package httpseq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+
+	"github.com/rogpeppe/ioseq"
+)
+
+type statusError int
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("status %d", int(e))
+}
+
+func (e statusError) StatusCode() int {
+	return int(e)
+}
+
+func TestServeSeqSized(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	err := ServeSeq(rec, req, ioseq.WithSize(ioseq.SeqFromString("hello"), 5), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Header().Get("Content-Length"); got != "5" {
+		t.Errorf("unexpected Content-Length %q", got)
+	}
+	if got := rec.Body.String(); got != "hello" {
+		t.Errorf("unexpected body %q", got)
+	}
+	if rec.Flushed {
+		t.Errorf("response unexpectedly flushed")
+	}
+}
+
+func TestServeSeqFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	err := ServeSeq(rec, req, ioseq.SeqFromString("hello"), &ServeOptions{Flush: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec.Flushed {
+		t.Errorf("response not flushed")
+	}
+	if got := rec.Header().Get("Content-Length"); got != "" {
+		t.Errorf("unexpected Content-Length %q", got)
+	}
+}
+
+func TestServeSeqErrorBeforeData(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		want int
+	}{
+		{statusError(http.StatusServiceUnavailable), http.StatusServiceUnavailable},
+		{errors.New("oops"), http.StatusInternalServerError},
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		err := ServeSeq(rec, req, ioseq.WithSize(ioseq.ErrorSeq(test.err), 5), nil)
+		if err != test.err {
+			t.Errorf("unexpected error %v", err)
+		}
+		if rec.Code != test.want {
+			t.Errorf("unexpected status; got %d want %d", rec.Code, test.want)
+		}
+		if got := rec.Header().Get("Content-Length"); got == "5" {
+			t.Errorf("Content-Length not removed")
+		}
+	}
+}
+
+func TestServeSeqErrorAfterData(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		seq := ioseq.ConcatSeqs(ioseq.SeqFromString("hello"), ioseq.ErrorSeq(errors.New("oops")))
+		ServeSeq(w, req, seq, &ServeOptions{Flush: true})
+		t.Errorf("ServeSeq returned")
+	}))
+	defer srv.Close()
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if string(data) != "hello" {
+		t.Errorf("unexpected data %q", data)
+	}
+	if err == nil {
+		t.Errorf("truncated response was not reported as an error")
+	}
+}
+
+func TestServeSeqCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(ctx, "GET", "/", nil)
+	err := ServeSeq(rec, req, ioseq.SeqFromString("hello"), nil)
+	if err != context.Canceled {
+		t.Errorf("unexpected error %v", err)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("unexpected body %q", rec.Body)
+	}
+}
+
+func TestServeSeqReaderSeq(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, []byte("hello world"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer f.Close()
+		if err := ServeSeq(w, req, ioseq.ReaderSeq{Reader: f}, nil); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil || string(data) != "hello world" {
+		t.Errorf("unexpected body %q, %v", data, err)
+	}
+	if resp.ContentLength != 11 {
+		t.Errorf("unexpected ContentLength %d", resp.ContentLength)
+	}
+}
+
+func TestServeSeqReaderSeqError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	readErr := statusError(http.StatusBadGateway)
+	err := ServeSeq(rec, req, ioseq.ReaderSeq{Reader: iotest.ErrReader(readErr)}, nil)
+	if err != readErr {
+		t.Errorf("unexpected error %v", err)
+	}
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("unexpected status %d", rec.Code)
+	}
+}
@@ -0,0 +1,91 @@
+package ioseq
+
+import (
+	"fmt"
+	"iter"
+)
+
+// SeqFromIter returns a [Seq] that yields each element of seq. If errf
+// is non-nil, it is called when seq has finished, and any error it
+// returns is yielded as the final element. This makes it possible to
+// use a plain iterator with no error path, or one that reports its
+// error separately, where a Seq is required.
+func SeqFromIter(seq iter.Seq[[]byte], errf func() error) Seq {
+	return func(yield func([]byte, error) bool) {
+		for data := range seq {
+			if !yield(data, nil) {
+				return
+			}
+		}
+		if errf != nil {
+			if err := errf(); err != nil {
+				yield(nil, err)
+			}
+		}
+	}
+}
+
+// IterFromSeq returns an iterator that yields the data from seq, for
+// use when seq is known never to fail. It panics if seq yields an
+// error.
+func IterFromSeq(seq Seq) iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		for data, err := range seq {
+			if err != nil {
+				panic(fmt.Errorf("ioseq: unexpected error from infallible sequence: %w", err))
+			}
+			if !yield(data) {
+				return
+			}
+		}
+	}
+}
+
+// SplitErr returns an iterator that yields the data from seq, along
+// with a pointer to an error that will be set to the error that
+// terminated seq, if any, when the iteration finishes. This allows a
+// single-variable range loop with the error checked afterwards:
+//
+//	data, errp := ioseq.SplitErr(seq)
+//	for chunk := range data {
+//		...
+//	}
+//	if err := *errp; err != nil {
+//		...
+//	}
+func SplitErr(seq Seq) (iter.Seq[[]byte], *error) {
+	errp := new(error)
+	return func(yield func([]byte) bool) {
+		*errp = nil
+		for data, err := range seq {
+			if err != nil {
+				*errp = err
+				return
+			}
+			if !yield(data) {
+				return
+			}
+		}
+	}, errp
+}
+
+// SplitFinalSeq returns a [Seq] that yields the same elements as seq
+// except that if the final element holds both data and an error, the
+// data and the error are yielded as separate elements. This converts a
+// sequence that uses the data-with-error extension described in the
+// [Seq] documentation into one that follows the usual rules.
+func SplitFinalSeq(seq Seq) Seq {
+	return func(yield func([]byte, error) bool) {
+		for data, err := range seq {
+			if err != nil && data != nil {
+				if !yield(data, nil) {
+					return
+				}
+				data = nil
+			}
+			if !yield(data, err) || err != nil {
+				return
+			}
+		}
+	}
+}
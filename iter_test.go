@@ -0,0 +1,92 @@
+package ioseq
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSeqFromIter(t *testing.T) {
+	in := slices.Values([][]byte{[]byte("foo"), []byte("bar")})
+	got, err := collectStrings(SeqFromIter(in, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"foo", "bar"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	someErr := fmt.Errorf("some error")
+	got, err = collectStrings(SeqFromIter(in, func() error {
+		return someErr
+	}))
+	if want := []string{"foo", "bar"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if err != someErr {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestIterFromSeq(t *testing.T) {
+	var got []string
+	for data := range IterFromSeq(seqOfStrings("foo", "bar")) {
+		got = append(got, string(data))
+	}
+	if want := []string{"foo", "bar"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestIterFromSeqPanicsOnError(t *testing.T) {
+	someErr := fmt.Errorf("some error")
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, someErr) {
+			t.Errorf("unexpected panic value %v", err)
+		}
+	}()
+	for range IterFromSeq(ErrorSeq(someErr)) {
+	}
+	t.Errorf("no panic")
+}
+
+func TestSplitErr(t *testing.T) {
+	someErr := fmt.Errorf("some error")
+	data, errp := SplitErr(ConcatSeqs(seqOfStrings("foo", "bar"), ErrorSeq(someErr)))
+	var got []string
+	for chunk := range data {
+		got = append(got, string(chunk))
+	}
+	if want := []string{"foo", "bar"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if *errp != someErr {
+		t.Errorf("unexpected error %v", *errp)
+	}
+}
+
+func TestSplitFinalSeq(t *testing.T) {
+	seqErr := errors.New("some error")
+	got, err := collectStrings(SplitFinalSeq(finalDataSeq(seqErr)))
+	if want := []string{"hello", "world"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected data; got %q want %q", got, want)
+	}
+	if err != seqErr {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := ReadAllSeq(ValidateSeq(SplitFinalSeq(finalDataSeq(seqErr)))); err != seqErr {
+		t.Errorf("unexpected error from validated sequence: %v", err)
+	}
+}
+
+// finalDataSeq returns a sequence whose final element
+// holds both data and err.
+func finalDataSeq(err error) Seq {
+	return func(yield func([]byte, error) bool) {
+		if !yield([]byte("hello"), nil) {
+			return
+		}
+		yield([]byte("world"), err)
+	}
+}
@@ -0,0 +1,41 @@
+package ioseq
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+var leakHandler atomic.Pointer[func(stack []byte)]
+
+// SetReaderLeakHandler enables detection of readers created by
+// [ReaderFromSeq], [NewSeqReader] and [ReaderWithContent] that become
+// unreachable without being closed. Such readers can hold on to
+// resources held by the underlying sequence indefinitely.
+//
+// When f is non-nil, the stack of each subsequently created reader is
+// recorded, and f is called with that stack when the garbage collector
+// finds that the reader has been leaked. Recording the stack is
+// relatively expensive, so this is best enabled only in tests or when
+// debugging. f is called in a separate goroutine, as described in
+// [runtime.AddCleanup]. Calling SetReaderLeakHandler with a nil f
+// disables detection for readers created after the call.
+//
+// Not all leaks can be detected: in particular a reader created by
+// [ReaderWithContent] remains reachable from its generating function
+// once reading has started.
+func SetReaderLeakHandler(f func(stack []byte)) {
+	if f == nil {
+		leakHandler.Store(nil)
+		return
+	}
+	leakHandler.Store(&f)
+}
+
+// trackLeak arranges for the leak handler, if any,
+// to be called if r is not closed.
+func (r *SeqReader) trackLeak() {
+	if f := leakHandler.Load(); f != nil {
+		r.cleanup = runtime.AddCleanup(r, *f, debug.Stack())
+	}
+}
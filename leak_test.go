@@ -0,0 +1,49 @@
+package ioseq
+
+import (
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetReaderLeakHandler(t *testing.T) {
+	leaked := make(chan []byte, 10)
+	SetReaderLeakHandler(func(stack []byte) {
+		leaked <- stack
+	})
+	defer SetReaderLeakHandler(nil)
+
+	// A reader that's closed should not be reported.
+	r := ReaderFromSeq(seqOfStrings("hello", "world"))
+	io.ReadFull(r, make([]byte, 2))
+	r.Close()
+
+	newLeakedReader()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		runtime.GC()
+		select {
+		case stack := <-leaked:
+			if !strings.Contains(string(stack), "newLeakedReader") {
+				t.Errorf("leak stack does not mention creator:\n%s", stack)
+			}
+			select {
+			case <-leaked:
+				t.Errorf("closed reader reported as leaked")
+			case <-time.After(100 * time.Millisecond):
+			}
+			return
+		case <-timeout:
+			t.Fatalf("leak not detected")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func newLeakedReader() {
+	r := ReaderFromSeq(seqOfStrings("hello", "world"))
+	io.ReadFull(r, make([]byte, 2))
+}
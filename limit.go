@@ -0,0 +1,62 @@
+package ioseq
+
+// LimitSeq returns a [Seq] that yields at most n bytes from seq,
+// slicing the final chunk if necessary. Iteration over seq stops as
+// soon as n bytes have been yielded. It is the Seq analogue of
+// [io.LimitReader].
+func LimitSeq(seq Seq, n int64) Seq {
+	return func(yield func([]byte, error) bool) {
+		if n <= 0 {
+			return
+		}
+		remain := n
+		for data, err := range seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if int64(len(data)) >= remain {
+				yield(data[:remain], nil)
+				return
+			}
+			if !yield(data, nil) {
+				return
+			}
+			remain -= int64(len(data))
+		}
+	}
+}
+
+// SkipSeq returns a [Seq] that yields the data from seq with
+// the first n bytes discarded. If seq holds n bytes or fewer,
+// the returned sequence is empty.
+func SkipSeq(seq Seq, n int64) Seq {
+	return func(yield func([]byte, error) bool) {
+		skip := n
+		for data, err := range seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if skip > 0 {
+				if int64(len(data)) <= skip {
+					skip -= int64(len(data))
+					continue
+				}
+				data = data[skip:]
+				skip = 0
+			}
+			if !yield(data, nil) {
+				return
+			}
+		}
+	}
+}
+
+// SectionSeq returns a [Seq] that yields the n bytes of seq
+// starting at offset off, in the same way that [io.SectionReader]
+// operates on an [io.ReaderAt]. Because seq is a stream, the first
+// off bytes must still be produced and are discarded.
+func SectionSeq(seq Seq, off, n int64) Seq {
+	return LimitSeq(SkipSeq(seq, off), n)
+}
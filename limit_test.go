@@ -0,0 +1,108 @@
+package ioseq
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+var sectionSeqTests = []struct {
+	testName string
+	in       []string
+	off, n   int64
+	want     []string
+}{{
+	testName: "Whole",
+	in:       []string{"abc", "def"},
+	off:      0,
+	n:        6,
+	want:     []string{"abc", "def"},
+}, {
+	testName: "ZeroLength",
+	in:       []string{"abc", "def"},
+	off:      1,
+	n:        0,
+	want:     nil,
+}, {
+	testName: "WithinChunk",
+	in:       []string{"abcdef"},
+	off:      1,
+	n:        3,
+	want:     []string{"bcd"},
+}, {
+	testName: "AcrossChunks",
+	in:       []string{"ab", "cd", "ef", "gh"},
+	off:      3,
+	n:        4,
+	want:     []string{"d", "ef", "g"},
+}, {
+	testName: "OnChunkBoundaries",
+	in:       []string{"ab", "cd", "ef", "gh"},
+	off:      2,
+	n:        4,
+	want:     []string{"cd", "ef"},
+}, {
+	testName: "PastEnd",
+	in:       []string{"ab", "cd"},
+	off:      3,
+	n:        10,
+	want:     []string{"d"},
+}, {
+	testName: "OffsetPastEnd",
+	in:       []string{"ab", "cd"},
+	off:      10,
+	n:        10,
+	want:     nil,
+}}
+
+func TestSectionSeq(t *testing.T) {
+	for _, test := range sectionSeqTests {
+		t.Run(test.testName, func(t *testing.T) {
+			got, err := collectStrings(SectionSeq(seqOfStrings(test.in...), test.off, test.n))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("unexpected results;\ngot %q\nwant %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLimitSeqStopsIteration(t *testing.T) {
+	produced := 0
+	in := func(yield func([]byte, error) bool) {
+		for {
+			produced++
+			if !yield([]byte("abc"), nil) {
+				return
+			}
+		}
+	}
+	got, err := collectStrings(LimitSeq(in, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"abc", "abc", "a"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if produced != 3 {
+		t.Errorf("unexpected chunk count; got %d want 3", produced)
+	}
+}
+
+func TestSkipSeqError(t *testing.T) {
+	in := func(yield func([]byte, error) bool) {
+		if !yield([]byte("abc"), nil) {
+			return
+		}
+		yield(nil, fmt.Errorf("some error"))
+	}
+	got, err := collectStrings(SkipSeq(in, 5))
+	if len(got) != 0 {
+		t.Errorf("unexpected results %q", got)
+	}
+	if err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
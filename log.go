@@ -0,0 +1,61 @@
+package ioseq
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// LogSeq returns a [Seq] that yields the same elements as seq, logging
+// its progress to logger at debug level: the size of each chunk along
+// with the total number of bytes so far, and, when iteration finishes,
+// the reason it finished, the totals and the time taken. Each message
+// has a "seq" attribute holding the given name.
+//
+// The reason is one of "end" (seq finished without error), "error"
+// (seq yielded an error, which is included as the "err" attribute)
+// or "stopped" (the consumer stopped iterating early).
+//
+// Nothing is logged if debug logging is not enabled when iteration
+// starts.
+func LogSeq(seq Seq, logger *slog.Logger, name string) Seq {
+	return func(yield func([]byte, error) bool) {
+		ctx := context.Background()
+		if !logger.Enabled(ctx, slog.LevelDebug) {
+			seq(yield)
+			return
+		}
+		log := logger.With("seq", name)
+		start := time.Now()
+		log.DebugContext(ctx, "sequence started")
+		var total, chunks int64
+		reason := "stopped"
+		var seqErr error
+		defer func() {
+			attrs := []any{
+				"reason", reason,
+				"bytes", total,
+				"chunks", chunks,
+				"duration", time.Since(start),
+			}
+			if seqErr != nil {
+				attrs = append(attrs, "err", seqErr)
+			}
+			log.DebugContext(ctx, "sequence finished", attrs...)
+		}()
+		for data, err := range seq {
+			if err != nil {
+				reason, seqErr = "error", err
+				yield(nil, err)
+				return
+			}
+			total += int64(len(data))
+			chunks++
+			log.DebugContext(ctx, "chunk", "size", len(data), "total", total)
+			if !yield(data, nil) {
+				return
+			}
+		}
+		reason = "end"
+	}
+}
@@ -0,0 +1,82 @@
+package ioseq
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogSeq(t *testing.T) {
+	tests := []struct {
+		testName string
+		seq      Seq
+		stop     bool
+		want     []string
+	}{{
+		testName: "End",
+		seq:      seqOfStrings("hello", "world!"),
+		want: []string{
+			`level=DEBUG msg="sequence started" seq=test`,
+			`level=DEBUG msg=chunk seq=test size=5 total=5`,
+			`level=DEBUG msg=chunk seq=test size=6 total=11`,
+			`level=DEBUG msg="sequence finished" seq=test reason=end bytes=11 chunks=2 duration=X`,
+		},
+	}, {
+		testName: "Error",
+		seq:      ConcatSeqs(seqOfStrings("hello"), ErrorSeq(errors.New("oops"))),
+		want: []string{
+			`level=DEBUG msg="sequence started" seq=test`,
+			`level=DEBUG msg=chunk seq=test size=5 total=5`,
+			`level=DEBUG msg="sequence finished" seq=test reason=error bytes=5 chunks=1 duration=X err=oops`,
+		},
+	}, {
+		testName: "Stopped",
+		seq:      seqOfStrings("hello", "world!"),
+		stop:     true,
+		want: []string{
+			`level=DEBUG msg="sequence started" seq=test`,
+			`level=DEBUG msg=chunk seq=test size=5 total=5`,
+			`level=DEBUG msg="sequence finished" seq=test reason=stopped bytes=5 chunks=1 duration=X`,
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+				Level: slog.LevelDebug,
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					switch a.Key {
+					case slog.TimeKey:
+						return slog.Attr{}
+					case "duration":
+						return slog.String("duration", "X")
+					}
+					return a
+				},
+			}))
+			for _, err := range LogSeq(test.seq, logger, "test") {
+				if test.stop || err != nil {
+					break
+				}
+			}
+			got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("unexpected log output;\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestLogSeqDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	got, err := ReadAllSeq(LogSeq(seqOfStrings("a", "b"), logger, "test"))
+	if string(got) != "ab" || err != nil {
+		t.Errorf("unexpected result %q, %v", got, err)
+	}
+	if buf.Len() > 0 {
+		t.Errorf("unexpected log output %q", buf.String())
+	}
+}
@@ -0,0 +1,150 @@
+package ioseq
+
+import (
+	"expvar"
+	"io"
+	"time"
+)
+
+// Metrics is implemented by types that record measurements of the data
+// flowing through sequences. Each measurement is associated with the
+// name of a stage, such as one of the stages in a [Pipeline].
+// See [MetricsSeq], [CopySeqMetrics], [PipeSeqThroughMetrics],
+// [ReaderFromSeqMetrics], [WithMetrics] and [Pipeline.WithMetrics].
+//
+// Methods may be called concurrently for different stages.
+type Metrics interface {
+	// AddChunk records that a chunk of n bytes
+	// was yielded by the stage.
+	AddChunk(stage string, n int)
+
+	// AddError records that the stage
+	// finished with the given error.
+	AddError(stage string, err error)
+
+	// AddDuration records that an iteration over the stage
+	// took the given time, including time spent by
+	// the consumer.
+	AddDuration(stage string, d time.Duration)
+}
+
+// MetricsSeq returns a [Seq] that yields the same elements as seq,
+// recording them in m under the given stage name.
+//
+// The bytes flowing into a stage are those flowing out of the
+// previous one, so wrapping the output of each stage is sufficient
+// to measure a whole pipeline.
+func MetricsSeq(seq Seq, m Metrics, stage string) Seq {
+	return func(yield func([]byte, error) bool) {
+		start := time.Now()
+		defer func() {
+			m.AddDuration(stage, time.Since(start))
+		}()
+		for data, err := range seq {
+			if err == nil || data != nil {
+				m.AddChunk(stage, len(data))
+			}
+			if err != nil {
+				m.AddError(stage, err)
+				yield(data, err)
+				return
+			}
+			if !yield(data, nil) {
+				return
+			}
+		}
+	}
+}
+
+// CopySeqMetrics is like [CopySeq] but records the copy in m under the
+// given stage name: each successful write to w is recorded as a chunk,
+// and any error, whether from seq or from w, is recorded when the copy
+// finishes.
+func CopySeqMetrics(w io.Writer, seq Seq, m Metrics, stage string) (int64, error) {
+	start := time.Now()
+	defer func() {
+		m.AddDuration(stage, time.Since(start))
+	}()
+	n, err := CopySeq(metricsWriter{w, m, stage}, seq)
+	if err != nil {
+		m.AddError(stage, err)
+	}
+	return n, err
+}
+
+// metricsWriter records the data written to w
+// as chunks of the given stage.
+type metricsWriter struct {
+	w     io.Writer
+	m     Metrics
+	stage string
+}
+
+func (w metricsWriter) Write(buf []byte) (int, error) {
+	n, err := w.w.Write(buf)
+	if n > 0 {
+		w.m.AddChunk(w.stage, n)
+	}
+	return n, err
+}
+
+// PipeSeqThroughMetrics is like [PipeSeqThrough] but records the data
+// flowing into and out of f in m: the elements of seq are recorded
+// under the stage name with ".in" appended, and the elements yielded
+// by the returned sequence under the stage name itself.
+func PipeSeqThroughMetrics[W io.WriteCloser](seq Seq, f func(w io.Writer) W, m Metrics, stage string) Seq {
+	return MetricsSeq(PipeSeqThrough(MetricsSeq(seq, m, stage+".in"), f), m, stage)
+}
+
+// ReaderFromSeqMetrics is like [ReaderFromSeq] but records the elements
+// pulled from seq in m under the given stage name. See [WithMetrics]
+// for the other direction.
+func ReaderFromSeqMetrics(seq Seq, m Metrics, stage string) io.ReadCloser {
+	return ReaderFromSeq(MetricsSeq(seq, m, stage))
+}
+
+// ExpvarMetrics is an implementation of [Metrics] that publishes its
+// measurements with the expvar package. For each stage, it maintains
+// the following integer variables in its map, where the stage name
+// is substituted for STAGE:
+//
+//	STAGE.bytes        total number of bytes yielded
+//	STAGE.chunks       total number of chunks yielded
+//	STAGE.errors       number of iterations that ended in error
+//	STAGE.iterations   number of iterations
+//	STAGE.nanoseconds  total time spent in iterations
+type ExpvarMetrics struct {
+	m *expvar.Map
+}
+
+// NewExpvarMetrics returns an [ExpvarMetrics] that publishes
+// its measurements as an [expvar.Map] with the given name.
+// As with [expvar.Publish], it panics if the name is already
+// in use.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	return &ExpvarMetrics{
+		m: expvar.NewMap(name),
+	}
+}
+
+// Map returns the map holding the published variables.
+func (m *ExpvarMetrics) Map() *expvar.Map {
+	return m.m
+}
+
+// AddChunk implements [Metrics.AddChunk].
+func (m *ExpvarMetrics) AddChunk(stage string, n int) {
+	m.m.Add(stage+".bytes", int64(n))
+	m.m.Add(stage+".chunks", 1)
+}
+
+// AddError implements [Metrics.AddError].
+func (m *ExpvarMetrics) AddError(stage string, err error) {
+	m.m.Add(stage+".errors", 1)
+}
+
+// AddDuration implements [Metrics.AddDuration].
+func (m *ExpvarMetrics) AddDuration(stage string, d time.Duration) {
+	m.m.Add(stage+".iterations", 1)
+	m.m.Add(stage+".nanoseconds", int64(d))
+}
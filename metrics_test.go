@@ -0,0 +1,182 @@
+package ioseq
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// recordingMetrics is an implementation of Metrics
+// that records the totals for each stage.
+type recordingMetrics struct {
+	mu     sync.Mutex
+	bytes  map[string]int
+	chunks map[string]int
+	errors map[string][]error
+	iters  map[string]int
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{
+		bytes:  make(map[string]int),
+		chunks: make(map[string]int),
+		errors: make(map[string][]error),
+		iters:  make(map[string]int),
+	}
+}
+
+func (m *recordingMetrics) AddChunk(stage string, n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bytes[stage] += n
+	m.chunks[stage]++
+}
+
+func (m *recordingMetrics) AddError(stage string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors[stage] = append(m.errors[stage], err)
+}
+
+func (m *recordingMetrics) AddDuration(stage string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.iters[stage]++
+}
+
+func TestMetricsSeq(t *testing.T) {
+	seqErr := errors.New("some error")
+	m := newRecordingMetrics()
+	seq := MetricsSeq(ConcatSeqs(seqOfStrings("hello", "world!"), ErrorSeq(seqErr)), m, "test")
+	if _, err := ReadAllSeq(seq); err != seqErr {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if m.bytes["test"] != 11 || m.chunks["test"] != 2 || m.iters["test"] != 1 {
+		t.Errorf("unexpected metrics: bytes %d, chunks %d, iterations %d", m.bytes["test"], m.chunks["test"], m.iters["test"])
+	}
+	if errs := m.errors["test"]; len(errs) != 1 || errs[0] != seqErr {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	m := NewExpvarMetrics("ioseq_test_metrics")
+	if expvar.Get("ioseq_test_metrics") != m.Map() {
+		t.Fatalf("metrics map not published")
+	}
+	seq := MetricsSeq(seqOfStrings("hello", "world!"), m, "test")
+	for range 2 {
+		if _, err := DiscardSeq(seq); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := map[string]int64{
+		"test.bytes":      22,
+		"test.chunks":     4,
+		"test.iterations": 2,
+	}
+	for key, val := range want {
+		v, _ := m.Map().Get(key).(*expvar.Int)
+		if v == nil || v.Value() != val {
+			t.Errorf("unexpected value for %s; got %v want %d", key, v, val)
+		}
+	}
+	if v := m.Map().Get("test.errors"); v != nil {
+		t.Errorf("unexpected errors value %v", v)
+	}
+}
+
+func TestCopySeqMetrics(t *testing.T) {
+	m := newRecordingMetrics()
+	var buf bytes.Buffer
+	n, err := CopySeqMetrics(&buf, seqOfStrings("hello", "world!"), m, "copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 11 || buf.String() != "helloworld!" {
+		t.Errorf("unexpected result %d %q", n, buf.String())
+	}
+	if m.bytes["copy"] != 11 || m.chunks["copy"] != 2 || m.iters["copy"] != 1 {
+		t.Errorf("unexpected metrics: bytes %d, chunks %d, iterations %d", m.bytes["copy"], m.chunks["copy"], m.iters["copy"])
+	}
+
+	// Write errors are recorded too.
+	writeErr := errors.New("write error")
+	m = newRecordingMetrics()
+	_, err = CopySeqMetrics(&limitedWriter{err: writeErr}, seqOfStrings("hello"), m, "copy")
+	if err != writeErr {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if errs := m.errors["copy"]; len(errs) != 1 || errs[0] != writeErr {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	if m.chunks["copy"] != 0 {
+		t.Errorf("unexpected chunk count %d", m.chunks["copy"])
+	}
+}
+
+func TestPipeSeqThroughMetrics(t *testing.T) {
+	m := newRecordingMetrics()
+	seq := PipeSeqThroughMetrics(seqOfStrings("hello", "world!"), func(w io.Writer) *gzip.Writer {
+		return gzip.NewWriter(w)
+	}, m, "gzip")
+	compressed, err := ReadAllSeq(seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.bytes["gzip.in"] != 11 || m.chunks["gzip.in"] != 2 || m.iters["gzip.in"] != 1 {
+		t.Errorf("unexpected input metrics: bytes %d, chunks %d, iterations %d", m.bytes["gzip.in"], m.chunks["gzip.in"], m.iters["gzip.in"])
+	}
+	if m.bytes["gzip"] != len(compressed) || m.iters["gzip"] != 1 {
+		t.Errorf("unexpected output metrics: bytes %d, iterations %d", m.bytes["gzip"], m.iters["gzip"])
+	}
+}
+
+func TestConversionMetrics(t *testing.T) {
+	m := newRecordingMetrics()
+	seq := SeqFromReaderWith(strings.NewReader("hello world"), WithoutWriterTo(), WithBufferSize(4), WithMetrics(m, "read"))
+	if _, err := DiscardSeq(seq); err != nil {
+		t.Fatal(err)
+	}
+	if m.bytes["read"] != 11 || m.chunks["read"] != 3 || m.iters["read"] != 1 {
+		t.Errorf("unexpected metrics: bytes %d, chunks %d, iterations %d", m.bytes["read"], m.chunks["read"], m.iters["read"])
+	}
+
+	// The data yielded with a final error is recorded as a chunk.
+	readErr := errors.New("read error")
+	m = newRecordingMetrics()
+	r := iotest.DataErrReader(io.MultiReader(strings.NewReader("hello"), iotest.ErrReader(readErr)))
+	var got []string
+	for data, err := range SeqFromReaderWith(r, WithoutWriterTo(), WithFinalData(), WithMetrics(m, "read")) {
+		got = append(got, fmt.Sprintf("%q %v", data, err))
+	}
+	if want := []string{`"hello" read error`}; !slices.Equal(got, want) {
+		t.Errorf("unexpected elements; got %q want %q", got, want)
+	}
+	if m.bytes["read"] != 5 || m.chunks["read"] != 1 || len(m.errors["read"]) != 1 {
+		t.Errorf("unexpected metrics: bytes %d, chunks %d, errors %v", m.bytes["read"], m.chunks["read"], m.errors["read"])
+	}
+
+	m = newRecordingMetrics()
+	rc := ReaderFromSeqMetrics(seqOfStrings("hello", "world!"), m, "reader")
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc.Close()
+	if string(data) != "helloworld!" {
+		t.Errorf("unexpected data %q", data)
+	}
+	if m.bytes["reader"] != 11 || m.chunks["reader"] != 2 || m.iters["reader"] != 1 {
+		t.Errorf("unexpected metrics: bytes %d, chunks %d, iterations %d", m.bytes["reader"], m.chunks["reader"], m.iters["reader"])
+	}
+}
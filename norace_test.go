@@ -0,0 +1,5 @@
+//go:build !race
+
+package ioseq
+
+const raceEnabled = false
@@ -0,0 +1,49 @@
+package ioseq
+
+import "fmt"
+
+// OffsetError is yielded by the sequence returned by [OffsetErrorSeq].
+// It records the byte offset at which an error occurred.
+type OffsetError struct {
+	// Off holds the number of bytes successfully
+	// yielded before the error.
+	Off int64
+	Err error
+}
+
+func (e *OffsetError) Error() string {
+	return fmt.Sprintf("at offset %d: %v", e.Off, e.Err)
+}
+
+// Offset returns e.Off.
+func (e *OffsetError) Offset() int64 {
+	return e.Off
+}
+
+func (e *OffsetError) Unwrap() error {
+	return e.Err
+}
+
+// OffsetErrorSeq returns a [Seq] that yields the same elements as seq
+// except that an error is wrapped in an [*OffsetError] recording the
+// total number of bytes yielded before it. This is useful for
+// reporting where a long transfer failed and for deciding where to
+// resume it.
+func OffsetErrorSeq(seq Seq) Seq {
+	return func(yield func([]byte, error) bool) {
+		var off int64
+		for data, err := range seq {
+			if err != nil {
+				yield(nil, &OffsetError{
+					Off: off,
+					Err: err,
+				})
+				return
+			}
+			if !yield(data, nil) {
+				return
+			}
+			off += int64(len(data))
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package ioseq
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOffsetErrorSeq(t *testing.T) {
+	seqErr := errors.New("some error")
+	data, err := ReadAllSeq(OffsetErrorSeq(ConcatSeqs(seqOfStrings("hello", "world!"), ErrorSeq(seqErr))))
+	if string(data) != "helloworld!" {
+		t.Errorf("unexpected data %q", data)
+	}
+	var offErr interface{ Offset() int64 }
+	if !errors.As(err, &offErr) {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if offErr.Offset() != 11 {
+		t.Errorf("unexpected offset %d", offErr.Offset())
+	}
+	if !errors.Is(err, seqErr) {
+		t.Errorf("error does not wrap original error")
+	}
+	if got, want := err.Error(), "at offset 11: some error"; got != want {
+		t.Errorf("unexpected error message; got %q want %q", got, want)
+	}
+}
+
+func TestOffsetErrorSeqNoError(t *testing.T) {
+	data, err := ReadAllSeq(OffsetErrorSeq(seqOfStrings("a", "b")))
+	if string(data) != "ab" || err != nil {
+		t.Errorf("unexpected result %q, %v", data, err)
+	}
+}
@@ -0,0 +1,77 @@
+// Package otelseq traces byte sequences of the kind used by package
+// [github.com/rogpeppe/ioseq] with OpenTelemetry.
+//
+// It lives in its own module so that the ioseq module itself has no
+// dependencies outside the standard library.
+package otelseq
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rogpeppe/ioseq"
+)
+
+// Attribute keys used to record statistics on a span.
+const (
+	// BytesKey records the number of bytes yielded.
+	BytesKey = attribute.Key("ioseq.bytes")
+
+	// ChunksKey records the number of non-error elements yielded.
+	ChunksKey = attribute.Key("ioseq.chunks")
+)
+
+// NewTracer returns an [ioseq.Tracer] that starts a span with t for
+// each stage, named after the stage. When the stage finishes, the
+// number of bytes and chunks it yielded are recorded on the span with
+// [BytesKey] and [ChunksKey], and any error is recorded and sets the
+// status of the span.
+//
+// It can be passed to [ioseq.Pipeline.WithTracer].
+func NewTracer(t trace.Tracer) ioseq.Tracer {
+	return tracer{t}
+}
+
+type tracer struct {
+	t trace.Tracer
+}
+
+// StartStage implements [ioseq.Tracer.StartStage].
+func (t tracer) StartStage(ctx context.Context, stage string) (context.Context, func(stats *ioseq.Stats)) {
+	ctx, span := t.t.Start(ctx, stage)
+	return ctx, func(stats *ioseq.Stats) {
+		endSpan(span, stats)
+	}
+}
+
+// Seq returns a [ioseq.Seq] that yields the same elements as seq,
+// tracing each iteration over it with a span started with t as a child
+// of any span in ctx. The span is recorded as described in [NewTracer].
+//
+// The span covers the whole iteration, including time spent by the
+// consumer, and is ended when the iteration finishes, whether because
+// seq ended or because the consumer stopped early.
+func Seq(ctx context.Context, t trace.Tracer, name string, seq ioseq.Seq) ioseq.Seq {
+	return func(yield func([]byte, error) bool) {
+		_, span := t.Start(ctx, name)
+		var stats ioseq.Stats
+		defer endSpan(span, &stats)
+		ioseq.StatsSeq(seq, &stats)(yield)
+	}
+}
+
+// endSpan records stats on span and ends it.
+func endSpan(span trace.Span, stats *ioseq.Stats) {
+	span.SetAttributes(
+		BytesKey.Int64(stats.Bytes),
+		ChunksKey.Int64(stats.Chunks),
+	)
+	if stats.Err != nil {
+		span.RecordError(stats.Err)
+		span.SetStatus(codes.Error, stats.Err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,115 @@
+package otelseq_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/rogpeppe/ioseq"
+	"github.com/rogpeppe/ioseq/otelseq"
+)
+
+func TestSeq(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer("test")
+
+	seqErr := errors.New("some error")
+	input := func(yield func([]byte, error) bool) {
+		if !yield([]byte("hello "), nil) {
+			return
+		}
+		if !yield([]byte("world"), nil) {
+			return
+		}
+		yield(nil, seqErr)
+	}
+	seq := otelseq.Seq(context.Background(), tracer, "input", input)
+	if _, err := ioseq.DiscardSeq(seq); err != seqErr {
+		t.Fatalf("unexpected error; got %v want %v", err, seqErr)
+	}
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("unexpected span count %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "input" {
+		t.Errorf("unexpected span name %q", span.Name())
+	}
+	checkAttrs(t, span, 11, 2)
+	if got := span.Status(); got.Code != codes.Error || got.Description != seqErr.Error() {
+		t.Errorf("unexpected status %v", got)
+	}
+	if events := span.Events(); len(events) != 1 || events[0].Name != "exception" {
+		t.Errorf("error not recorded; events %v", events)
+	}
+
+	// Stopping early ends the span without an error.
+	rec = tracetest.NewSpanRecorder()
+	tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer("test")
+	for range otelseq.Seq(context.Background(), tracer, "input", input) {
+		break
+	}
+	spans = rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("unexpected span count %d", len(spans))
+	}
+	checkAttrs(t, spans[0], 6, 1)
+	if got := spans[0].Status(); got.Code != codes.Unset {
+		t.Errorf("unexpected status %v", got)
+	}
+}
+
+func TestNewTracer(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer("test")
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	err := ioseq.NewPipeline(ioseq.SeqFromBytes([]byte("hello world"))).
+		WithTracer(otelseq.NewTracer(tracer)).
+		Then("rechunk", func(ctx context.Context, in ioseq.Seq) ioseq.Seq {
+			return ioseq.RechunkSeq(in, 2)
+		}).
+		Run(ctx, func(ctx context.Context, seq ioseq.Seq) error {
+			_, err := ioseq.DiscardSeq(seq)
+			return err
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	var names []string
+	for _, span := range rec.Ended() {
+		names = append(names, span.Name())
+		if span.Name() == "parent" {
+			continue
+		}
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("span %q is not a child of the parent span", span.Name())
+		}
+		if span.Name() == "rechunk" {
+			checkAttrs(t, span, 11, 6)
+		}
+	}
+	slices.Sort(names)
+	if want := []string{"parent", "rechunk", "sink", "source"}; !slices.Equal(names, want) {
+		t.Errorf("unexpected spans; got %q want %q", names, want)
+	}
+}
+
+func checkAttrs(t *testing.T, span sdktrace.ReadOnlySpan, bytes, chunks int64) {
+	t.Helper()
+	want := []attribute.KeyValue{
+		otelseq.BytesKey.Int64(bytes),
+		otelseq.ChunksKey.Int64(chunks),
+	}
+	if got := span.Attributes(); !slices.Equal(got, want) {
+		t.Errorf("unexpected attributes for span %q; got %v want %v", span.Name(), got, want)
+	}
+}
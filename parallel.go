@@ -0,0 +1,119 @@
+package ioseq
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// ParallelPipeSeqThrough is like [PipeSeqThrough] except that each
+// chunk of seq is piped through a separate instance of the filter,
+// created by calling f, and up to n chunks are processed concurrently.
+// The output for each chunk is yielded in the same order as the input,
+// as a single element.
+//
+// Because each chunk is filtered independently, this is only suitable
+// for filters where that makes sense: for example, compressing each
+// chunk with [gzip.NewWriter] produces a valid multi-member gzip
+// stream, and base64-encoding chunks whose sizes are multiples of three
+// gives the same result as encoding the whole. [RechunkSeq] can be
+// used to control the size of the chunks.
+//
+// The chunks are copied, so at most about 2*n chunks of input and
+// their output are held in memory at once. If the iteration stops
+// early, it does not finish until all the filters in progress have
+// finished.
+//
+// ParallelPipeSeqThrough panics if n is not positive.
+func ParallelPipeSeqThrough[W io.WriteCloser](seq Seq, f func(w io.Writer) W, n int) Seq {
+	if n <= 0 {
+		panic("ioseq: non-positive parallelism")
+	}
+	return func(yield func([]byte, error) bool) {
+		type result struct {
+			data []byte
+			err  error
+		}
+		type job struct {
+			in     []byte
+			result chan result
+		}
+		// jobs holds jobs waiting for a worker.
+		jobs := make(chan job)
+		// pending holds jobs in input order.
+		pending := make(chan job, n)
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		defer func() {
+			close(done)
+			wg.Wait()
+		}()
+		for range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := range jobs {
+					data, err := filterChunk(f, j.in)
+					j.result <- result{data, err}
+				}
+			}()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(pending)
+			defer close(jobs)
+			for data, err := range seq {
+				j := job{
+					result: make(chan result, 1),
+				}
+				if err != nil {
+					j.result <- result{err: err}
+					select {
+					case pending <- j:
+					case <-done:
+					}
+					return
+				}
+				j.in = bytes.Clone(data)
+				// Add the job to pending before handing it to
+				// a worker so that the consumer always
+				// waits for jobs in the order they were started.
+				select {
+				case pending <- j:
+				case <-done:
+					return
+				}
+				select {
+				case jobs <- j:
+				case <-done:
+					return
+				}
+			}
+		}()
+		for j := range pending {
+			r := <-j.result
+			if r.err != nil {
+				yield(nil, r.err)
+				return
+			}
+			if len(r.data) > 0 && !yield(r.data, nil) {
+				return
+			}
+		}
+	}
+}
+
+// filterChunk returns the result of writing data through
+// a filter created by f.
+func filterChunk[W io.WriteCloser](f func(w io.Writer) W, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := f(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,86 @@
+package ioseq
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParallelPipeSeqThrough(t *testing.T) {
+	text := strings.Repeat("some text to encode ", 1000)
+	in := RechunkSeq(SeqFromString(text), 300)
+	// Sleep randomly in the filter so that the chunks
+	// are likely to finish out of order.
+	newEncoder := func(w io.Writer) io.WriteCloser {
+		time.Sleep(time.Duration(rand.IntN(1000)) * time.Microsecond)
+		return base64.NewEncoder(base64.StdEncoding, w)
+	}
+	data, err := ReadAllSeq(ParallelPipeSeqThrough(in, newEncoder, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), base64.StdEncoding.EncodeToString([]byte(text)); got != want {
+		t.Errorf("unexpected result")
+	}
+}
+
+func TestParallelPipeSeqThroughGzip(t *testing.T) {
+	text := strings.Repeat("some text to compress ", 10000)
+	in := RechunkSeq(SeqFromString(text), 8192)
+	data, err := ReadAllSeq(ParallelPipeSeqThrough(in, gzip.NewWriter, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != text {
+		t.Errorf("unexpected result")
+	}
+}
+
+func TestParallelPipeSeqThroughError(t *testing.T) {
+	seqErr := errors.New("some error")
+	in := ConcatSeqs(seqOfStrings("abc", "def"), ErrorSeq(seqErr))
+	newEncoder := func(w io.Writer) io.WriteCloser {
+		return base64.NewEncoder(base64.StdEncoding, w)
+	}
+	got, err := collectStrings(ParallelPipeSeqThrough(in, newEncoder, 2))
+	if err != seqErr {
+		t.Errorf("unexpected error; got %v want %v", err, seqErr)
+	}
+	if got, want := strings.Join(got, ""), "YWJjZGVm"; got != want {
+		t.Errorf("unexpected result; got %q want %q", got, want)
+	}
+}
+
+func TestParallelPipeSeqThroughEarlyStop(t *testing.T) {
+	stopped := false
+	in := func(yield func([]byte, error) bool) {
+		defer func() {
+			stopped = true
+		}()
+		for yield([]byte("abc"), nil) {
+		}
+	}
+	newEncoder := func(w io.Writer) io.WriteCloser {
+		return base64.NewEncoder(base64.StdEncoding, w)
+	}
+	for range ParallelPipeSeqThrough(in, newEncoder, 4) {
+		break
+	}
+	if !stopped {
+		t.Errorf("producer not stopped")
+	}
+}
@@ -0,0 +1,174 @@
+package ioseq
+
+import (
+	"io"
+	"sync"
+)
+
+// SeqPipe creates a synchronous in-memory pipe, similar to [io.Pipe],
+// except that the read side is a [Seq]. Unlike [PipeSeqThrough], the
+// producer and consumer run in separate goroutines.
+//
+// Data written to the [SeqPipeWriter] is copied into an internal buffer
+// holding up to bufSize bytes; writes block while the buffer is full.
+// The consumer receives the buffered data in chunks of at most bufSize
+// bytes. In total, the pipe holds at most 2*bufSize bytes of buffer
+// space.
+//
+// SeqPipe panics if bufSize is not positive.
+func SeqPipe(bufSize int) (*SeqPipeReader, *SeqPipeWriter) {
+	if bufSize <= 0 {
+		panic("ioseq: non-positive buffer size")
+	}
+	p := &seqPipe{
+		buf:   make([]byte, 0, bufSize),
+		spare: make([]byte, 0, bufSize),
+	}
+	p.cond.L = &p.mu
+	return &SeqPipeReader{p}, &SeqPipeWriter{p}
+}
+
+type seqPipe struct {
+	mu sync.Mutex
+	// cond is broadcast whenever any of the fields below change.
+	cond sync.Cond
+
+	// buf holds data written but not yet consumed.
+	buf []byte
+	// spare holds the buffer that is not currently in
+	// use by the writer. The consumer owns it while
+	// it is being yielded.
+	spare []byte
+
+	rclosed bool
+	rerr    error
+	wclosed bool
+	werr    error
+}
+
+// SeqPipeReader is the read half of a pipe created by [SeqPipe].
+type SeqPipeReader struct {
+	p *seqPipe
+}
+
+// Seq returns a [Seq] that yields the data written to the pipe. It
+// ends when the write half is closed, yielding the error passed to
+// [SeqPipeWriter.CloseWithError] if it was not nil.
+//
+// The returned sequence must be iterated over at most once. If the
+// iteration stops early, the read half of the pipe is closed, as if by
+// [SeqPipeReader.Close].
+func (r *SeqPipeReader) Seq() Seq {
+	return func(yield func([]byte, error) bool) {
+		p := r.p
+		for {
+			p.mu.Lock()
+			for len(p.buf) == 0 && !p.wclosed && !p.rclosed {
+				p.cond.Wait()
+			}
+			if p.rclosed {
+				p.mu.Unlock()
+				yield(nil, io.ErrClosedPipe)
+				return
+			}
+			if len(p.buf) == 0 {
+				err := p.werr
+				p.mu.Unlock()
+				if err != nil {
+					yield(nil, err)
+				}
+				return
+			}
+			chunk := p.buf
+			p.buf, p.spare = p.spare[:0], nil
+			p.cond.Broadcast()
+			p.mu.Unlock()
+
+			ok := yield(chunk, nil)
+
+			p.mu.Lock()
+			p.spare = chunk
+			p.mu.Unlock()
+			if !ok {
+				r.Close()
+				return
+			}
+		}
+	}
+}
+
+// Close closes the reader; subsequent writes to the write half of the
+// pipe will return [io.ErrClosedPipe].
+func (r *SeqPipeReader) Close() error {
+	return r.CloseWithError(nil)
+}
+
+// CloseWithError closes the reader; subsequent writes to the write half
+// of the pipe will return err, or [io.ErrClosedPipe] if err is nil.
+// Only the first call has any effect.
+func (r *SeqPipeReader) CloseWithError(err error) error {
+	if err == nil {
+		err = io.ErrClosedPipe
+	}
+	p := r.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.rclosed {
+		p.rclosed = true
+		p.rerr = err
+		p.cond.Broadcast()
+	}
+	return nil
+}
+
+// SeqPipeWriter is the write half of a pipe created by [SeqPipe].
+type SeqPipeWriter struct {
+	p *seqPipe
+}
+
+// Write implements [io.Writer]. It copies data into the pipe's buffer,
+// blocking until there is space for all of it, or the pipe is closed.
+func (w *SeqPipeWriter) Write(data []byte) (int, error) {
+	p := w.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for len(data) > 0 {
+		for !p.rclosed && !p.wclosed && len(p.buf) == cap(p.buf) {
+			p.cond.Wait()
+		}
+		if p.rclosed {
+			return n, p.rerr
+		}
+		if p.wclosed {
+			return n, io.ErrClosedPipe
+		}
+		m := min(len(data), cap(p.buf)-len(p.buf))
+		p.buf = append(p.buf, data[:m]...)
+		data = data[m:]
+		n += m
+		p.cond.Broadcast()
+	}
+	return n, nil
+}
+
+// Close closes the writer; the reader's sequence will end
+// after yielding any data remaining in the buffer.
+func (w *SeqPipeWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+// CloseWithError closes the writer; the reader's sequence will yield
+// any remaining buffered data followed by err, if it is not nil.
+// Only the first call has any effect.
+func (w *SeqPipeWriter) CloseWithError(err error) error {
+	p := w.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.wclosed {
+		p.wclosed = true
+		p.werr = err
+		p.cond.Broadcast()
+	}
+	return nil
+}
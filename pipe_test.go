@@ -0,0 +1,81 @@
+package ioseq
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSeqPipe(t *testing.T) {
+	r, w := SeqPipe(4)
+	want := strings.Repeat("hello world ", 100)
+	go func() {
+		for i := 0; i < len(want); i += 7 {
+			if _, err := io.WriteString(w, want[i:min(i+7, len(want))]); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		w.Close()
+	}()
+	var sb strings.Builder
+	for data, err := range r.Seq() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > 4 {
+			t.Fatalf("chunk too large: %q", data)
+		}
+		sb.Write(data)
+	}
+	if got := sb.String(); got != want {
+		t.Fatalf("unexpected data; got %q want %q", got, want)
+	}
+}
+
+func TestSeqPipeWriterCloseWithError(t *testing.T) {
+	r, w := SeqPipe(10)
+	writeErr := errors.New("write error")
+	go func() {
+		io.WriteString(w, "some data")
+		w.CloseWithError(writeErr)
+	}()
+	got, err := collectStrings(r.Seq())
+	if err != writeErr {
+		t.Fatalf("unexpected error; got %v want %v", err, writeErr)
+	}
+	if got := strings.Join(got, ""); got != "some data" {
+		t.Fatalf("unexpected data %q", got)
+	}
+}
+
+func TestSeqPipeReaderCloseWithError(t *testing.T) {
+	r, w := SeqPipe(2)
+	readErr := errors.New("read error")
+	done := make(chan error)
+	go func() {
+		// The write must block because it's larger than the buffer.
+		_, err := io.WriteString(w, "some data")
+		done <- err
+	}()
+	r.CloseWithError(readErr)
+	if err := <-done; err != readErr {
+		t.Fatalf("unexpected write error; got %v want %v", err, readErr)
+	}
+}
+
+func TestSeqPipeEarlyStop(t *testing.T) {
+	r, w := SeqPipe(2)
+	done := make(chan error)
+	go func() {
+		_, err := io.WriteString(w, "some data")
+		done <- err
+	}()
+	for range r.Seq() {
+		break
+	}
+	if err := <-done; err != io.ErrClosedPipe {
+		t.Fatalf("unexpected write error; got %v want %v", err, io.ErrClosedPipe)
+	}
+}
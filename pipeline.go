@@ -0,0 +1,272 @@
+package ioseq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/pprof"
+)
+
+// Pipeline connects a source [Seq] through a series of named stages to
+// a sink. It is built with [NewPipeline] and [Pipeline.Then] and run
+// with [Pipeline.Run].
+//
+// All the stages share a context that is cancelled when the pipeline
+// finishes, so stages that start goroutines or make requests can
+// observe it. Errors are attributed to the stage that produced them
+// with [*StageError].
+type Pipeline struct {
+	source     Seq
+	stages     []pipelineStage
+	metrics    Metrics
+	classifier ErrorClassifier
+	tracer     Tracer
+}
+
+type pipelineStage struct {
+	name  string
+	f     func(ctx context.Context, in Seq) Seq
+	async int
+}
+
+// StageError is the error returned by [Pipeline.Run]
+// when a stage fails.
+type StageError struct {
+	// Stage holds the name of the stage that failed. The source
+	// is named "source" and the sink is named "sink".
+	Stage string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("stage %s: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// NewPipeline returns a new pipeline that reads from source.
+func NewPipeline(source Seq) *Pipeline {
+	return &Pipeline{
+		source: source,
+	}
+}
+
+// Then adds a stage to the end of the pipeline. The stage is
+// constructed by calling f with the pipeline's context and the output
+// of the previous stage when the pipeline runs. Errors yielded by the
+// stage that did not come from its input are attributed to it.
+//
+// It returns p.
+func (p *Pipeline) Then(name string, f func(ctx context.Context, in Seq) Seq) *Pipeline {
+	p.stages = append(p.stages, pipelineStage{
+		name: name,
+		f:    f,
+	})
+	return p
+}
+
+// ThenStage is like [Pipeline.Then] but adds the given stage,
+// which does not use the context.
+//
+// It returns p.
+func (p *Pipeline) ThenStage(s Stage) *Pipeline {
+	return p.Then(s.Name, func(_ context.Context, in Seq) Seq {
+		return s.Transform(in)
+	})
+}
+
+// ThenAsync is like [Pipeline.Then] except that the stage runs in
+// its own goroutine, concurrently with the rest of the pipeline, with
+// up to n chunks of its output buffered, as with [AsyncSeq].
+//
+// It returns p.
+func (p *Pipeline) ThenAsync(name string, n int, f func(ctx context.Context, in Seq) Seq) *Pipeline {
+	p.stages = append(p.stages, pipelineStage{
+		name:  name,
+		f:     f,
+		async: max(n, 1),
+	})
+	return p
+}
+
+// WithMetrics arranges for the output of the source and each stage to
+// be recorded in m when the pipeline runs, as if by [MetricsSeq],
+// using the names of the stages.
+//
+// It returns p.
+func (p *Pipeline) WithMetrics(m Metrics) *Pipeline {
+	p.metrics = m
+	return p
+}
+
+// WithTracer arranges for the source, each stage and the sink to be
+// traced with t when the pipeline runs. The context returned by
+// [Tracer.StartStage] is the one passed to the stage, so a span that
+// it holds is the parent of any spans started by the stage itself.
+//
+// It returns p.
+func (p *Pipeline) WithTracer(t Tracer) *Pipeline {
+	p.tracer = t
+	return p
+}
+
+// WithErrorClassifier causes Run to treat errors that c classifies as
+// [ErrorStop], such as [context.Canceled] when using
+// [DefaultErrorClassifier], as a clean stop rather than a failure.
+// When the source or a stage yields such an error, its output ends
+// there without an error, so later stages and the sink see the end of
+// the data; when the sink returns one, Run returns nil. The error
+// passed to c may be a [*StageError], so c should use [errors.Is] or
+// [errors.As] to inspect it.
+//
+// It returns p.
+func (p *Pipeline) WithErrorClassifier(c ErrorClassifier) *Pipeline {
+	p.classifier = c
+	return p
+}
+
+// Run runs the pipeline, passing the output of the last stage to sink,
+// and returns the first error encountered. If the error came from a
+// stage, the source or the sink, it is a [*StageError] identifying
+// where it happened. The context passed to the stages and the sink is
+// derived from ctx and is cancelled when Run returns.
+//
+// While the code for the source, a stage or the sink is running, the
+// goroutine has a pprof label "stage" holding its name (see
+// [runtime/pprof.SetGoroutineLabels]), so that CPU profiles attribute
+// time to the right stage. The labels are reset to those in ctx when
+// Run returns. See [Pipeline.WithTracer] for tracing the stages.
+//
+// Cancelling ctx stops the pipeline when the source
+// next produces data.
+func (p *Pipeline) Run(ctx context.Context, sink func(ctx context.Context, seq Seq) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer pprof.SetGoroutineLabels(ctx)
+
+	// Each element is labelled with the stage that consumes it,
+	// so that we can restore the consumer's labels when yielding.
+	names := make([]string, 0, len(p.stages)+2)
+	names = append(names, "source")
+	for _, s := range p.stages {
+		names = append(names, s.name)
+	}
+	names = append(names, "sink")
+	ctxs := make([]context.Context, len(names))
+	traces := make([]*stageTrace, len(names))
+	for i, name := range names {
+		ctxs[i], traces[i] = p.startStage(stageContext(ctx, name), name)
+	}
+	defer func() {
+		for _, t := range traces {
+			t.finish(&Stats{})
+		}
+	}()
+	sinkCtx := ctxs[len(ctxs)-1]
+
+	seq := SeqWithContext(ctx, p.source)
+	seq = labelSeq(ctxs[0], ctxs[1], traces[0].seq(p.measure("source", p.stopErrors(attributeErrors("source", seq)))))
+	for i, s := range p.stages {
+		seq = labelSeq(ctxs[i+1], ctxs[i+2], traces[i+1].seq(p.measure(s.name, p.stopErrors(attributeErrors(s.name, s.f(ctxs[i+1], seq))))))
+		if s.async > 0 {
+			seq = AsyncSeq(seq, s.async)
+		}
+	}
+	pprof.SetGoroutineLabels(sinkCtx)
+	var sinkStats Stats
+	if traces[len(traces)-1] != nil {
+		seq = StatsSeq(seq, &sinkStats)
+	}
+	err := sink(sinkCtx, seq)
+	if err != nil {
+		sinkStats.Err = err
+	}
+	traces[len(traces)-1].finish(&sinkStats)
+	if err == nil || p.classifier != nil && p.classifier.ClassifyError(err) == ErrorStop {
+		return nil
+	}
+	return attributeError("sink", err)
+}
+
+// stageContext returns ctx with a pprof label
+// identifying the named stage.
+func stageContext(ctx context.Context, name string) context.Context {
+	return pprof.WithLabels(ctx, pprof.Labels("stage", name))
+}
+
+// labelSeq returns a sequence that yields the same elements as seq,
+// setting the goroutine's pprof labels to those in ctx while seq is
+// running and to those in consumerCtx while the consumer is.
+func labelSeq(ctx, consumerCtx context.Context, seq Seq) Seq {
+	return func(yield func([]byte, error) bool) {
+		pprof.SetGoroutineLabels(ctx)
+		defer pprof.SetGoroutineLabels(consumerCtx)
+		seq(func(data []byte, err error) bool {
+			pprof.SetGoroutineLabels(consumerCtx)
+			defer pprof.SetGoroutineLabels(ctx)
+			return yield(data, err)
+		})
+	}
+}
+
+// measure returns seq wrapped with [MetricsSeq]
+// if the pipeline has metrics enabled.
+func (p *Pipeline) measure(name string, seq Seq) Seq {
+	if p.metrics == nil {
+		return seq
+	}
+	return MetricsSeq(seq, p.metrics, name)
+}
+
+// stopErrors returns a sequence that yields the same elements as seq
+// except that it ends cleanly instead of yielding an error that the
+// pipeline's classifier classifies as [ErrorStop].
+func (p *Pipeline) stopErrors(seq Seq) Seq {
+	if p.classifier == nil {
+		return seq
+	}
+	return func(yield func([]byte, error) bool) {
+		for data, err := range seq {
+			if err != nil {
+				if p.classifier.ClassifyError(err) != ErrorStop {
+					yield(nil, err)
+				}
+				return
+			}
+			if !yield(data, nil) {
+				return
+			}
+		}
+	}
+}
+
+// attributeErrors returns a sequence that yields the same elements as
+// seq except that errors are attributed to the named stage.
+func attributeErrors(name string, seq Seq) Seq {
+	return func(yield func([]byte, error) bool) {
+		for data, err := range seq {
+			if err != nil {
+				yield(nil, attributeError(name, err))
+				return
+			}
+			if !yield(data, nil) {
+				return
+			}
+		}
+	}
+}
+
+// attributeError returns err as a [*StageError] with the given stage
+// name, unless it already holds a StageError from an earlier stage.
+func attributeError(name string, err error) error {
+	var stageErr *StageError
+	if errors.As(err, &stageErr) {
+		return err
+	}
+	return &StageError{
+		Stage: name,
+		Err:   err,
+	}
+}
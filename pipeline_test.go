@@ -0,0 +1,295 @@
+package ioseq
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	var got strings.Builder
+	err := NewPipeline(seqOfStrings("hello ", "world")).
+		Then("upper", func(ctx context.Context, in Seq) Seq {
+			return MapSeq(in, bytes.ToUpper)
+		}).
+		ThenAsync("exclaim", 2, func(ctx context.Context, in Seq) Seq {
+			return ConcatSeqs(in, SeqFromString("!"))
+		}).
+		Run(context.Background(), func(ctx context.Context, seq Seq) error {
+			_, err := CopySeq(&got, seq)
+			return err
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := got.String(), "HELLO WORLD!"; got != want {
+		t.Errorf("unexpected result; got %q want %q", got, want)
+	}
+}
+
+func TestPipelineErrorAttribution(t *testing.T) {
+	stageErr := errors.New("stage failure")
+	sourceErr := errors.New("source failure")
+	sinkErr := errors.New("sink failure")
+	tests := []struct {
+		testName  string
+		source    Seq
+		stageFail bool
+		sinkFail  bool
+		wantStage string
+		wantErr   error
+	}{{
+		testName:  "Source",
+		source:    ConcatSeqs(seqOfStrings("a"), ErrorSeq(sourceErr)),
+		wantStage: "source",
+		wantErr:   sourceErr,
+	}, {
+		testName:  "Stage",
+		source:    seqOfStrings("a"),
+		stageFail: true,
+		wantStage: "failing",
+		wantErr:   stageErr,
+	}, {
+		testName:  "Sink",
+		source:    seqOfStrings("a"),
+		sinkFail:  true,
+		wantStage: "sink",
+		wantErr:   sinkErr,
+	}}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			err := NewPipeline(test.source).
+				Then("passthrough", func(ctx context.Context, in Seq) Seq {
+					return in
+				}).
+				ThenAsync("failing", 1, func(ctx context.Context, in Seq) Seq {
+					if test.stageFail {
+						return ConcatSeqs(in, ErrorSeq(stageErr))
+					}
+					return in
+				}).
+				Run(context.Background(), func(ctx context.Context, seq Seq) error {
+					if _, err := DiscardSeq(seq); err != nil {
+						return err
+					}
+					if test.sinkFail {
+						return sinkErr
+					}
+					return nil
+				})
+			var serr *StageError
+			if !errors.As(err, &serr) {
+				t.Fatalf("unexpected error %#v", err)
+			}
+			if serr.Stage != test.wantStage || !errors.Is(err, test.wantErr) {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestPipelineContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	source := func(yield func([]byte, error) bool) {
+		for yield([]byte("data"), nil) {
+		}
+	}
+	err := NewPipeline(source).Run(ctx, func(ctx context.Context, seq Seq) error {
+		for _, err := range seq {
+			if err != nil {
+				return err
+			}
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestPipelineThenStage(t *testing.T) {
+	var got strings.Builder
+	err := NewPipeline(seqOfStrings("hello")).
+		ThenStage(Stage{
+			Name: "upper",
+			Transform: func(seq Seq) Seq {
+				return MapSeq(seq, bytes.ToUpper)
+			},
+		}).
+		Run(context.Background(), func(ctx context.Context, seq Seq) error {
+			_, err := CopySeq(&got, seq)
+			return err
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := got.String(), "HELLO"; got != want {
+		t.Errorf("unexpected result; got %q want %q", got, want)
+	}
+}
+
+func TestPipelineWithMetrics(t *testing.T) {
+	m := newRecordingMetrics()
+	err := NewPipeline(seqOfStrings("hello ", "world")).
+		WithMetrics(m).
+		Then("split", func(ctx context.Context, in Seq) Seq {
+			return RechunkSeq(in, 2)
+		}).
+		Run(context.Background(), func(ctx context.Context, seq Seq) error {
+			_, err := DiscardSeq(seq)
+			return err
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.bytes["source"] != 11 || m.chunks["source"] != 2 {
+		t.Errorf("unexpected source metrics: bytes %d, chunks %d", m.bytes["source"], m.chunks["source"])
+	}
+	if m.bytes["split"] != 11 || m.chunks["split"] != 6 {
+		t.Errorf("unexpected split metrics: bytes %d, chunks %d", m.bytes["split"], m.chunks["split"])
+	}
+}
+
+func TestPipelineProfileLabels(t *testing.T) {
+	// stageLabel returns the pprof labels of the goroutine
+	// running this test function.
+	stageLabel := func() string {
+		var buf bytes.Buffer
+		pprof.Lookup("goroutine").WriteTo(&buf, 1)
+		for _, entry := range strings.Split(buf.String(), "\n\n") {
+			if !strings.Contains(entry, "TestPipelineProfileLabels") {
+				continue
+			}
+			_, labels, ok := strings.Cut(entry, "# labels: ")
+			if !ok {
+				return ""
+			}
+			labels, _, _ = strings.Cut(labels, "\n")
+			return labels
+		}
+		return ""
+	}
+	var got []string
+	err := NewPipeline(func(yield func([]byte, error) bool) {
+		got = append(got, "source "+stageLabel())
+		yield([]byte("x"), nil)
+	}).
+		Then("upper", func(ctx context.Context, in Seq) Seq {
+			return MapSeq(in, func(data []byte) []byte {
+				got = append(got, "upper "+stageLabel())
+				return data
+			})
+		}).
+		Run(context.Background(), func(ctx context.Context, seq Seq) error {
+			for range seq {
+				got = append(got, "sink "+stageLabel())
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`source {"stage":"source"}`,
+		`upper {"stage":"upper"}`,
+		`sink {"stage":"sink"}`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected labels;\ngot  %q\nwant %q", got, want)
+	}
+	if label := stageLabel(); label != "" {
+		t.Errorf("labels not restored; got %q", label)
+	}
+}
+
+type spanKey struct{}
+
+// recordingTracer is a [Tracer] that records the stages it sees.
+type recordingTracer struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (t *recordingTracer) StartStage(ctx context.Context, stage string) (context.Context, func(*Stats)) {
+	t.add("start %s", stage)
+	return context.WithValue(ctx, spanKey{}, stage), func(stats *Stats) {
+		t.add("end %s bytes %d chunks %d err %v", stage, stats.Bytes, stats.Chunks, stats.Err)
+	}
+}
+
+func (t *recordingTracer) add(f string, a ...any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, fmt.Sprintf(f, a...))
+}
+
+func TestPipelineWithTracer(t *testing.T) {
+	tracer := &recordingTracer{}
+	sinkErr := errors.New("sink failure")
+	var spans []any
+	err := NewPipeline(seqOfStrings("hello ", "world")).
+		WithTracer(tracer).
+		Then("split", func(ctx context.Context, in Seq) Seq {
+			spans = append(spans, ctx.Value(spanKey{}))
+			return RechunkSeq(in, 2)
+		}).
+		Then("unused", func(ctx context.Context, in Seq) Seq {
+			spans = append(spans, ctx.Value(spanKey{}))
+			return in
+		}).
+		Run(context.Background(), func(ctx context.Context, seq Seq) error {
+			spans = append(spans, ctx.Value(spanKey{}))
+			return sinkErr
+		})
+	if !errors.Is(err, sinkErr) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := []any{"split", "unused", "sink"}; !slices.Equal(spans, want) {
+		t.Errorf("unexpected stage spans; got %q want %q", spans, want)
+	}
+	want := []string{
+		"start source",
+		"start split",
+		"start unused",
+		"start sink",
+		"end sink bytes 0 chunks 0 err sink failure",
+		"end source bytes 0 chunks 0 err <nil>",
+		"end split bytes 0 chunks 0 err <nil>",
+		"end unused bytes 0 chunks 0 err <nil>",
+	}
+	if !slices.Equal(tracer.events, want) {
+		t.Errorf("unexpected events;\ngot %q\nwant %q", tracer.events, want)
+	}
+
+	tracer = &recordingTracer{}
+	err = NewPipeline(seqOfStrings("hello ", "world")).
+		WithTracer(tracer).
+		Then("split", func(ctx context.Context, in Seq) Seq {
+			return RechunkSeq(in, 2)
+		}).
+		Run(context.Background(), func(ctx context.Context, seq Seq) error {
+			_, err := DiscardSeq(seq)
+			return err
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		"start source",
+		"start split",
+		"start sink",
+		"end source bytes 11 chunks 2 err <nil>",
+		"end split bytes 11 chunks 6 err <nil>",
+		"end sink bytes 11 chunks 6 err <nil>",
+	}
+	if !slices.Equal(tracer.events, want) {
+		t.Errorf("unexpected events;\ngot %q\nwant %q", tracer.events, want)
+	}
+}
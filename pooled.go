@@ -0,0 +1,130 @@
+package ioseq
+
+import (
+	"io"
+	"iter"
+)
+
+// Chunk holds a buffer owned by whoever currently holds the chunk,
+// typically obtained from a [ChunkPool]. Unlike the slices yielded by
+// a [Seq], a Chunk yielded by a [ChunkSeq] is owned by the consumer,
+// which may retain it, pass it to another goroutine and so on, but
+// must call [Chunk.Release] exactly once when it has finished with it.
+//
+// This allows a producer to hand out buffers without either reusing a
+// single buffer (which prevents producer and consumer from running
+// concurrently) or allocating a new buffer for every chunk.
+//
+// The zero Chunk holds no data and Release does nothing.
+type Chunk struct {
+	buf  *[]byte
+	pool *ChunkPool
+}
+
+// NewChunk returns a chunk holding a buffer of length n obtained
+// from pool. Releasing the chunk returns the buffer to the pool.
+func NewChunk(pool *ChunkPool, n int) Chunk {
+	return Chunk{
+		buf:  pool.get(n),
+		pool: pool,
+	}
+}
+
+// Bytes returns the data held in the chunk. The returned
+// slice must not be used after the chunk has been released.
+func (c Chunk) Bytes() []byte {
+	if c.buf == nil {
+		return nil
+	}
+	return *c.buf
+}
+
+// Truncate shortens the chunk's data to n bytes.
+// It panics if n is greater than the current length.
+func (c Chunk) Truncate(n int) {
+	if n > len(c.Bytes()) {
+		panic("ioseq: Chunk.Truncate out of range")
+	}
+	if c.buf != nil {
+		*c.buf = (*c.buf)[:n]
+	}
+}
+
+// Release returns the chunk's buffer to its pool. Neither the
+// chunk nor any slice returned by [Chunk.Bytes] may be used
+// after calling Release.
+func (c Chunk) Release() {
+	if c.buf != nil && c.pool != nil {
+		c.pool.put(c.buf)
+	}
+}
+
+// ChunkSeq is like [Seq] except that it yields chunks that are owned
+// by the consumer. The same rules apply as for [Seq]: each element
+// holds either a chunk or an error, and the sequence finishes at the
+// first error. Every chunk yielded must be released by the consumer,
+// including the final one when the consumer stops iterating early.
+type ChunkSeq = iter.Seq2[Chunk, error]
+
+// ChunkSeqFromReader returns a [ChunkSeq] that reads from r into
+// chunks of at most bufSize bytes obtained from pool. In the steady
+// state, when the consumer releases each chunk before many more are
+// read, reading does not allocate.
+func ChunkSeqFromReader(r io.Reader, pool *ChunkPool, bufSize int) ChunkSeq {
+	return func(yield func(Chunk, error) bool) {
+		for {
+			c := NewChunk(pool, bufSize)
+			n, err := r.Read(c.Bytes())
+			if n > 0 {
+				c.Truncate(n)
+				if !yield(c, nil) {
+					return
+				}
+			} else {
+				c.Release()
+			}
+			if err != nil {
+				if err != io.EOF {
+					yield(Chunk{}, err)
+				}
+				return
+			}
+		}
+	}
+}
+
+// ChunkSeqFromSeq returns a [ChunkSeq] that yields a copy of each
+// chunk of seq, made into buffers obtained from pool.
+func ChunkSeqFromSeq(seq Seq, pool *ChunkPool) ChunkSeq {
+	return func(yield func(Chunk, error) bool) {
+		for data, err := range seq {
+			if err != nil {
+				yield(Chunk{}, err)
+				return
+			}
+			c := NewChunk(pool, len(data))
+			copy(c.Bytes(), data)
+			if !yield(c, nil) {
+				return
+			}
+		}
+	}
+}
+
+// SeqFromChunkSeq returns a [Seq] that yields the data in each chunk
+// of cs, releasing each chunk when the consumer has finished with it.
+func SeqFromChunkSeq(cs ChunkSeq) Seq {
+	return func(yield func([]byte, error) bool) {
+		for c, err := range cs {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			ok := yield(c.Bytes(), nil)
+			c.Release()
+			if !ok {
+				return
+			}
+		}
+	}
+}
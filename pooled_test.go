@@ -0,0 +1,97 @@
+package ioseq
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestChunkSeqFromReader(t *testing.T) {
+	var pool ChunkPool
+	src := strings.Repeat("abcdefghij", 100)
+	// Retain all the chunks, as a concurrent consumer might,
+	// to check that the buffers are not reused.
+	var chunks []Chunk
+	for c, err := range ChunkSeqFromReader(strings.NewReader(src), &pool, 64) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, c)
+	}
+	var got bytes.Buffer
+	for _, c := range chunks {
+		got.Write(c.Bytes())
+		c.Release()
+	}
+	if got.String() != src {
+		t.Errorf("unexpected content; got %q want %q", got.String(), src)
+	}
+}
+
+func TestChunkSeqFromReaderError(t *testing.T) {
+	var pool ChunkPool
+	readErr := errors.New("some error")
+	r := io.MultiReader(strings.NewReader("hello"), iotest.ErrReader(readErr))
+	data, err := ReadAllSeq(SeqFromChunkSeq(ChunkSeqFromReader(r, &pool, 64)))
+	if string(data) != "hello" {
+		t.Errorf("unexpected data %q", data)
+	}
+	if err != readErr {
+		t.Errorf("unexpected error; got %v want %v", err, readErr)
+	}
+}
+
+func TestChunkSeqFromSeq(t *testing.T) {
+	var pool ChunkPool
+	var retained []Chunk
+	for c, err := range ChunkSeqFromSeq(reusingSeq("foo", "bar", "baz"), &pool) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		retained = append(retained, c)
+	}
+	var got []string
+	for _, c := range retained {
+		got = append(got, string(c.Bytes()))
+		c.Release()
+	}
+	if want := "foo bar baz"; strings.Join(got, " ") != want {
+		t.Errorf("unexpected results; got %q want %q", got, want)
+	}
+}
+
+func TestChunkSeqAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items randomly under the race detector")
+	}
+	var pool ChunkPool
+	src := make([]byte, 256*1024)
+	r := bytes.NewReader(src)
+	// Exclude the allocations needed to fill the pool.
+	for c := range ChunkSeqFromReader(r, &pool, 1024) {
+		c.Release()
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		r.Reset(src)
+		for c := range ChunkSeqFromReader(r, &pool, 1024) {
+			c.Release()
+		}
+	})
+	// 256 chunks are read in each run, but only the
+	// sequence itself should need allocating.
+	if allocs > 4 {
+		t.Errorf("too many allocations: %v", allocs)
+	}
+}
+
+func TestZeroChunk(t *testing.T) {
+	var c Chunk
+	if c.Bytes() != nil {
+		t.Errorf("unexpected data in zero chunk")
+	}
+	c.Truncate(0)
+	c.Release()
+}
@@ -0,0 +1,60 @@
+package ioseq
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is yielded by the sequence returned by [ProtectSeq]
+// when the producer panics.
+type PanicError struct {
+	// Value holds the value passed to panic.
+	Value any
+
+	// Stack holds the stack trace of the goroutine
+	// at the time of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("ioseq: panic in sequence: %v\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// ProtectSeq returns a [Seq] that yields the same elements as seq,
+// except that if seq panics, the panic is recovered and the sequence
+// ends by yielding a [*PanicError] holding the panic value and stack.
+//
+// Panics raised by the consumer (that is, from within a call to yield)
+// are not recovered, nor are panics raised after the consumer has
+// stopped iterating, as there is no way to report them.
+func ProtectSeq(seq Seq) Seq {
+	return func(yield func([]byte, error) bool) {
+		inYield, stopped := false, false
+		defer func() {
+			if inYield || stopped {
+				// Don't recover: the panic came from the
+				// consumer or can't be reported to it.
+				return
+			}
+			if e := recover(); e != nil {
+				yield(nil, &PanicError{
+					Value: e,
+					Stack: debug.Stack(),
+				})
+			}
+		}()
+		seq(func(data []byte, err error) bool {
+			inYield = true
+			ok := yield(data, err)
+			inYield = false
+			stopped = !ok || err != nil
+			return ok
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package ioseq
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestProtectSeq(t *testing.T) {
+	panicErr := errors.New("some error")
+	seq := ProtectSeq(func(yield func([]byte, error) bool) {
+		if !yield([]byte("hello"), nil) {
+			return
+		}
+		panic(panicErr)
+	})
+	data, err := ReadAllSeq(seq)
+	if string(data) != "hello" {
+		t.Errorf("unexpected data %q", data)
+	}
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if perr.Value != panicErr {
+		t.Errorf("unexpected panic value %v", perr.Value)
+	}
+	if !errors.Is(err, panicErr) {
+		t.Errorf("error does not wrap panic value")
+	}
+	if !strings.Contains(string(perr.Stack), "TestProtectSeq") {
+		t.Errorf("stack does not mention test function:\n%s", perr.Stack)
+	}
+}
+
+func TestProtectSeqConsumerPanic(t *testing.T) {
+	defer func() {
+		if e := recover(); e != "consumer" {
+			t.Errorf("unexpected panic value %v", e)
+		}
+	}()
+	for range ProtectSeq(seqOfStrings("a", "b")) {
+		panic("consumer")
+	}
+	t.Errorf("panic not propagated")
+}
+
+func TestProtectSeqNoPanic(t *testing.T) {
+	got, err := ReadAllSeq(ProtectSeq(seqOfStrings("a", "b")))
+	if string(got) != "ab" || err != nil {
+		t.Errorf("unexpected result %q, %v", got, err)
+	}
+}
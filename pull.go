@@ -0,0 +1,119 @@
+package ioseq
+
+import (
+	"io"
+	"iter"
+)
+
+// PullReader provides pull-style access to the elements of a [Seq],
+// managing the functions returned by [iter.Pull2] so that a sequence
+// can be consumed incrementally across function boundaries.
+//
+// A slice returned by any PullReader method remains valid only until
+// the next method call.
+type PullReader struct {
+	next func() ([]byte, error, bool)
+	stop func()
+
+	// data holds the unconsumed part of the current chunk.
+	data []byte
+	// full is true when data holds a chunk, even if it's empty.
+	full bool
+	err  error
+}
+
+// NewPullReader returns a PullReader that reads from seq.
+// The Close method must be called when the caller has finished
+// with it.
+func NewPullReader(seq Seq) *PullReader {
+	next, stop := iter.Pull2(seq)
+	return &PullReader{
+		next: next,
+		stop: stop,
+	}
+}
+
+// fill makes sure that r.data holds the next chunk, if there is one,
+// and reports whether it does.
+func (r *PullReader) fill() bool {
+	if r.full {
+		return true
+	}
+	if r.err != nil {
+		return false
+	}
+	data, err, ok := r.next()
+	switch {
+	case !ok:
+		r.err = io.EOF
+	case err != nil:
+		r.err = err
+	default:
+		r.data, r.full = data, true
+	}
+	return r.full
+}
+
+// Next returns the next chunk from the sequence, or the rest of the
+// current chunk if it has been partially consumed by ReadFull. At the
+// end of the sequence it returns [io.EOF]; otherwise it returns any
+// error from the sequence.
+func (r *PullReader) Next() ([]byte, error) {
+	if !r.fill() {
+		return nil, r.err
+	}
+	data := r.data
+	r.data, r.full = nil, false
+	return data, nil
+}
+
+// Peek is like Next but does not consume the chunk,
+// so it will be returned again by the next call.
+func (r *PullReader) Peek() ([]byte, error) {
+	if !r.fill() {
+		return nil, r.err
+	}
+	return r.data, nil
+}
+
+// ReadFull reads exactly len(buf) bytes into buf from successive
+// chunks, with the same semantics as [io.ReadFull]: it returns
+// [io.EOF] only if no bytes were read, and [io.ErrUnexpectedEOF] if the
+// sequence ended part way through. Any unused part of the final chunk
+// is retained for subsequent calls.
+func (r *PullReader) ReadFull(buf []byte) (int, error) {
+	n := 0
+	for n < len(buf) {
+		if !r.fill() {
+			if r.err == io.EOF && n > 0 {
+				return n, io.ErrUnexpectedEOF
+			}
+			return n, r.err
+		}
+		m := copy(buf[n:], r.data)
+		n += m
+		r.advance(m)
+	}
+	return n, nil
+}
+
+// advance consumes n bytes of the current chunk, which
+// must have been filled.
+func (r *PullReader) advance(n int) {
+	r.data = r.data[n:]
+	if len(r.data) == 0 {
+		r.data, r.full = nil, false
+	}
+}
+
+// Close stops the underlying sequence. Subsequent calls
+// to Next, Peek and ReadFull will return [io.EOF] unless
+// the sequence has already failed.
+func (r *PullReader) Close() error {
+	r.stop()
+	r.data, r.full = nil, false
+	if r.err == nil {
+		r.err = io.EOF
+	}
+	return nil
+}
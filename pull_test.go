@@ -0,0 +1,76 @@
+package ioseq
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestPullReader(t *testing.T) {
+	r := NewPullReader(seqOfStrings("hello", "", "world"))
+	defer r.Close()
+
+	data, err := r.Peek()
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("unexpected Peek result %q, %v", data, err)
+	}
+	buf := make([]byte, 3)
+	if n, err := r.ReadFull(buf); err != nil || string(buf[:n]) != "hel" {
+		t.Fatalf("unexpected ReadFull result %q, %v", buf[:n], err)
+	}
+	data, err = r.Next()
+	if err != nil || string(data) != "lo" {
+		t.Fatalf("unexpected Next result %q, %v", data, err)
+	}
+	data, err = r.Next()
+	if err != nil || string(data) != "" {
+		t.Fatalf("unexpected Next result %q, %v", data, err)
+	}
+	buf = make([]byte, 10)
+	n, err := r.ReadFull(buf)
+	if err != io.ErrUnexpectedEOF || string(buf[:n]) != "world" {
+		t.Fatalf("unexpected ReadFull result %q, %v", buf[:n], err)
+	}
+	if n, err := r.ReadFull(buf); n != 0 || err != io.EOF {
+		t.Fatalf("unexpected ReadFull result %d, %v", n, err)
+	}
+	if data, err := r.Next(); data != nil || err != io.EOF {
+		t.Fatalf("unexpected Next result %q, %v", data, err)
+	}
+}
+
+func TestPullReaderError(t *testing.T) {
+	r := NewPullReader(ConcatSeqs(seqOfStrings("a"), ErrorSeq(fmt.Errorf("some error"))))
+	defer r.Close()
+	if data, err := r.Next(); err != nil || string(data) != "a" {
+		t.Fatalf("unexpected Next result %q, %v", data, err)
+	}
+	for range 2 {
+		if _, err := r.Peek(); err == nil || err.Error() != "some error" {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+}
+
+func TestPullReaderClose(t *testing.T) {
+	stopped := false
+	in := func(yield func([]byte, error) bool) {
+		defer func() {
+			stopped = true
+		}()
+		for {
+			if !yield([]byte("x"), nil) {
+				return
+			}
+		}
+	}
+	r := NewPullReader(in)
+	r.Next()
+	r.Close()
+	if !stopped {
+		t.Errorf("sequence was not stopped")
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("unexpected error after Close: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package ioseq
+
+import (
+	"context"
+	"time"
+)
+
+// Limiter is the interface used by [RateLimitSeq] to limit the rate
+// at which data is yielded. It is implemented by *rate.Limiter from
+// the golang.org/x/time/rate package.
+//
+// If a Limiter also implements the method Burst() int, as
+// *rate.Limiter does, no more than that many bytes are requested
+// in any single call to WaitN.
+type Limiter interface {
+	WaitN(ctx context.Context, n int) error
+}
+
+// RateLimitSeq returns a [Seq] that yields the same data as seq,
+// waiting before each chunk until lim permits that many bytes to
+// be sent. Chunks larger than the limiter's burst size are split.
+//
+// If WaitN returns an error (for example because ctx has been
+// cancelled), that error is yielded and the sequence ends.
+func RateLimitSeq(ctx context.Context, seq Seq, lim Limiter) Seq {
+	return func(yield func([]byte, error) bool) {
+		burst := -1
+		if lim, ok := lim.(interface{ Burst() int }); ok {
+			burst = lim.Burst()
+		}
+		for data, err := range seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if len(data) == 0 {
+				if !yield(data, nil) {
+					return
+				}
+				continue
+			}
+			for len(data) > 0 {
+				n := len(data)
+				if burst > 0 {
+					n = min(n, burst)
+				}
+				if err := lim.WaitN(ctx, n); err != nil {
+					yield(nil, err)
+					return
+				}
+				if !yield(data[:n:n], nil) {
+					return
+				}
+				data = data[n:]
+			}
+		}
+	}
+}
+
+// paceInterval is the granularity of the pacing done by PaceSeq.
+const paceInterval = 50 * time.Millisecond
+
+// PaceSeq returns a [Seq] that yields the same data as seq, but paced
+// so that it is delivered at a steady rate of bytesPerSecond, as a
+// live media stream might be. It sleeps before each chunk for a time
+// proportional to the size of the data already yielded. Chunks holding
+// more than 50ms worth of data are split so that delivery is smooth.
+//
+// Unlike [RateLimitSeq], PaceSeq does not allow bursts to make up for
+// lost time: if the consumer or producer falls behind the schedule,
+// pacing resumes from the current time rather than delivering data
+// faster to catch up.
+//
+// PaceSeq panics if bytesPerSecond is not positive.
+func PaceSeq(seq Seq, bytesPerSecond float64) Seq {
+	if bytesPerSecond <= 0 {
+		panic("ioseq: non-positive pace")
+	}
+	piece := max(1, int(bytesPerSecond*paceInterval.Seconds()))
+	return func(yield func([]byte, error) bool) {
+		start := time.Now()
+		sent := 0
+		for data, err := range seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for len(data) > 0 {
+				due := start.Add(time.Duration(float64(sent) / bytesPerSecond * float64(time.Second)))
+				if d := time.Until(due); d > 0 {
+					time.Sleep(d)
+				} else if d < -paceInterval {
+					// We've fallen behind; start again from now.
+					start, sent = time.Now(), 0
+				}
+				n := min(len(data), piece)
+				if !yield(data[:n:n], nil) {
+					return
+				}
+				sent += n
+				data = data[n:]
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package ioseq
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeLimiter struct {
+	burst int
+	waits []int
+	err   error
+}
+
+func (l *fakeLimiter) WaitN(ctx context.Context, n int) error {
+	if n > l.burst {
+		return fmt.Errorf("n %d exceeds burst %d", n, l.burst)
+	}
+	l.waits = append(l.waits, n)
+	return l.err
+}
+
+func (l *fakeLimiter) Burst() int {
+	return l.burst
+}
+
+func TestRateLimitSeq(t *testing.T) {
+	lim := &fakeLimiter{burst: 3}
+	got, err := collectStrings(RateLimitSeq(context.Background(), seqOfStrings("ab", "cdefgh", "i"), lim))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ab", "cde", "fgh", "i"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if want := []int{2, 3, 3, 1}; !slices.Equal(lim.waits, want) {
+		t.Errorf("unexpected waits; got %v want %v", lim.waits, want)
+	}
+}
+
+func TestRateLimitSeqError(t *testing.T) {
+	lim := &fakeLimiter{burst: 10, err: context.Canceled}
+	got, err := collectStrings(RateLimitSeq(context.Background(), seqOfStrings("ab", "cd"), lim))
+	if len(got) != 0 {
+		t.Errorf("unexpected results %q", got)
+	}
+	if err != context.Canceled {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestPaceSeq(t *testing.T) {
+	// 1000 bytes per second gives 50 byte pieces
+	// with 50ms between them.
+	start := time.Now()
+	got, err := collectStrings(PaceSeq(seqOfStrings(strings.Repeat("x", 120), "yy"), 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	elapsed := time.Since(start)
+	var sizes []int
+	for _, s := range got {
+		sizes = append(sizes, len(s))
+	}
+	if want := []int{50, 50, 20, 2}; !slices.Equal(sizes, want) {
+		t.Errorf("unexpected chunk sizes; got %v want %v", sizes, want)
+	}
+	// The last chunk is due after 120 bytes, which is 120ms.
+	if elapsed < 120*time.Millisecond {
+		t.Errorf("pacing too fast; took %v", elapsed)
+	}
+}
@@ -0,0 +1,256 @@
+package ioseq
+
+import "io"
+
+// ReaderOption is an option that can be passed to [SeqFromReaderWith].
+type ReaderOption func(*readerOptions)
+
+type readerOptions struct {
+	bufSize     int
+	pool        *ChunkPool
+	minRead     int
+	noWriterTo  bool
+	closeSource bool
+	readAhead   bool
+	finalData   bool
+	metrics     Metrics
+	stage       string
+}
+
+// WithBufferSize sets the size of the buffer used to read from the
+// reader. The default is [DefaultBufferSize].
+func WithBufferSize(n int) ReaderOption {
+	return func(o *readerOptions) {
+		o.bufSize = n
+	}
+}
+
+// WithBufferPool causes the read buffer to be taken from pool for the
+// duration of each iteration and returned to it afterwards, rather
+// than being allocated.
+func WithBufferPool(pool *ChunkPool) ReaderOption {
+	return func(o *readerOptions) {
+		o.pool = pool
+	}
+}
+
+// WithMinRead causes data to be accumulated until at least n bytes
+// (or the whole buffer, if that's smaller) have been read before it
+// is yielded, except at the end of the data. This reduces the number
+// of elements produced by readers that return small amounts of data
+// from each Read call. It implies [WithoutWriterTo].
+func WithMinRead(n int) ReaderOption {
+	return func(o *readerOptions) {
+		o.minRead = n
+	}
+}
+
+// WithoutWriterTo disables the use of the reader's [io.WriterTo]
+// implementation, so that data is always read into a buffer. This is
+// useful when the WriterTo implementation produces inconveniently
+// sized chunks.
+func WithoutWriterTo() ReaderOption {
+	return func(o *readerOptions) {
+		o.noWriterTo = true
+	}
+}
+
+// WithCloseSource causes the reader to be closed, if it implements
+// [io.Closer], when the iteration finishes, however it finishes.
+func WithCloseSource() ReaderOption {
+	return func(o *readerOptions) {
+		o.closeSource = true
+	}
+}
+
+// WithReadAhead causes the next Read call to be made in a separate
+// goroutine while the consumer is processing the current chunk, using
+// two buffers, so that I/O latency overlaps with the consumer's work.
+// It implies [WithoutWriterTo].
+//
+// If the iteration stops early, the goroutine finishes when its
+// current Read call returns; until then it may still be reading
+// from the reader, including after it has been closed when
+// [WithCloseSource] is used.
+func WithReadAhead() ReaderOption {
+	return func(o *readerOptions) {
+		o.readAhead = true
+	}
+}
+
+// WithFinalData causes a Read call that returns both data and an
+// error other than [io.EOF] to be yielded as a single element holding
+// both, rather than as two elements, so that a consumer sees the data
+// and the error together. The resulting sequence uses the extension
+// described in the [Seq] documentation, so it must only be passed to
+// consumers that support it. It has no effect when the reader's
+// [io.WriterTo] implementation is used.
+func WithFinalData() ReaderOption {
+	return func(o *readerOptions) {
+		o.finalData = true
+	}
+}
+
+// WithMetrics causes the elements of the sequence to be recorded in m
+// under the given stage name, as by [MetricsSeq].
+func WithMetrics(m Metrics, stage string) ReaderOption {
+	return func(o *readerOptions) {
+		o.metrics = m
+		o.stage = stage
+	}
+}
+
+// SeqFromReaderWith is like [SeqFromReader] but its behavior can be
+// customized with options. With no options, it is equivalent to
+// SeqFromReader(r, DefaultBufferSize).
+func SeqFromReaderWith(r io.Reader, opts ...ReaderOption) Seq {
+	o := readerOptions{
+		bufSize: DefaultBufferSize,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.bufSize <= 0 {
+		panic("ioseq: non-positive buffer size")
+	}
+	var seq Seq
+	switch _, isWriterTo := r.(io.WriterTo); {
+	case o.readAhead:
+		seq = o.readAheadSeq(r)
+	case isWriterTo && !o.noWriterTo && o.minRead <= 0:
+		seq = SeqFromReader(r, 0)
+	default:
+		seq = o.readSeq(r)
+	}
+	if o.metrics != nil {
+		seq = MetricsSeq(seq, o.metrics, o.stage)
+	}
+	if !o.closeSource {
+		return seq
+	}
+	return func(yield func([]byte, error) bool) {
+		if c, ok := r.(io.Closer); ok {
+			defer c.Close()
+		}
+		seq(yield)
+	}
+}
+
+// getBuf returns a new read buffer.
+func (o *readerOptions) getBuf() []byte {
+	if o.pool != nil {
+		return o.pool.Get(o.bufSize)
+	}
+	return make([]byte, o.bufSize)
+}
+
+// putBuf releases a buffer returned by getBuf.
+func (o *readerOptions) putBuf(buf []byte) {
+	if o.pool != nil {
+		o.pool.Put(buf)
+	}
+}
+
+// read reads from r into buf until at least the configured minimum
+// has been read or an error occurs. It returns the number of bytes
+// read, any error other than [io.EOF], and whether the end of the
+// data has been reached.
+func (o *readerOptions) read(r io.Reader, buf []byte) (int, error, bool) {
+	minRead := max(1, min(o.minRead, len(buf)))
+	n := 0
+	var err error
+	for n < minRead && err == nil {
+		var m int
+		m, err = r.Read(buf[n:])
+		n += m
+	}
+	if err == io.EOF {
+		return n, nil, true
+	}
+	return n, err, false
+}
+
+// readSeq returns a sequence that reads from r into a buffer.
+func (o *readerOptions) readSeq(r io.Reader) Seq {
+	return func(yield func([]byte, error) bool) {
+		buf := o.getBuf()
+		defer o.putBuf(buf)
+		for {
+			n, err, done := o.read(r, buf)
+			if n > 0 && err != nil && o.finalData {
+				yield(buf[:n], err)
+				return
+			}
+			if n > 0 && !yield(buf[:n], nil) {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if done {
+				return
+			}
+		}
+	}
+}
+
+// readAheadSeq returns a sequence that reads from r in a separate
+// goroutine, alternating between two buffers.
+func (o *readerOptions) readAheadSeq(r io.Reader) Seq {
+	return func(yield func([]byte, error) bool) {
+		type chunk struct {
+			buf  []byte
+			err  error
+			done bool
+		}
+		// free holds buffers that are available for reading into.
+		free := make(chan []byte, 2)
+		free <- o.getBuf()
+		free <- o.getBuf()
+		chunks := make(chan chunk, 2)
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			for {
+				var buf []byte
+				select {
+				case buf = <-free:
+				case <-stop:
+					return
+				}
+				n, err, done := o.read(r, buf)
+				// chunks has room for both buffers,
+				// so this never blocks.
+				chunks <- chunk{buf[:n], err, done}
+				if err != nil || done {
+					return
+				}
+			}
+		}()
+		for c := range chunks {
+			if len(c.buf) > 0 && c.err != nil && o.finalData {
+				yield(c.buf, c.err)
+				o.putBuf(c.buf)
+				o.putBuf(<-free)
+				return
+			}
+			if len(c.buf) > 0 && !yield(c.buf, nil) {
+				// The reading goroutine may still be using
+				// the other buffer, so we can't return
+				// either of them to the pool.
+				return
+			}
+			if c.err != nil {
+				yield(nil, c.err)
+				return
+			}
+			if c.done {
+				o.putBuf(c.buf)
+				o.putBuf(<-free)
+				return
+			}
+			free <- c.buf[:cap(c.buf)]
+		}
+	}
+}
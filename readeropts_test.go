@@ -0,0 +1,179 @@
+package ioseq
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestSeqFromReaderWith(t *testing.T) {
+	// strings.Reader implements WriterTo, so by default
+	// its data is yielded in one piece.
+	got, err := collectStrings(SeqFromReaderWith(strings.NewReader("hello world")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"hello world"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results; got %q want %q", got, want)
+	}
+
+	got, err = collectStrings(SeqFromReaderWith(
+		strings.NewReader("hello world"),
+		WithoutWriterTo(),
+		WithBufferSize(4),
+		WithBufferPool(new(ChunkPool)),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"hell", "o wo", "rld"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results; got %q want %q", got, want)
+	}
+
+	got, err = collectStrings(SeqFromReaderWith(
+		iotest.OneByteReader(strings.NewReader("hello world")),
+		WithMinRead(3),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"hel", "lo ", "wor", "ld"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results; got %q want %q", got, want)
+	}
+}
+
+func TestSeqFromReaderWithCloseSource(t *testing.T) {
+	r := &closeRecorder{Reader: strings.NewReader("hello world")}
+	for range SeqFromReaderWith(r, WithCloseSource()) {
+		break
+	}
+	if !r.closed {
+		t.Errorf("source not closed after early stop")
+	}
+
+	r = &closeRecorder{Reader: strings.NewReader("hello world")}
+	for range SeqFromReaderWith(r) {
+	}
+	if r.closed {
+		t.Errorf("source unexpectedly closed")
+	}
+}
+
+type closeRecorder struct {
+	*strings.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestSeqFromReaderWithReadAhead(t *testing.T) {
+	text := strings.Repeat("hello world ", 1000)
+	got, err := collectStrings(SeqFromReaderWith(
+		iotest.OneByteReader(strings.NewReader(text)),
+		WithReadAhead(),
+		WithMinRead(100),
+		WithBufferSize(100),
+		WithBufferPool(new(ChunkPool)),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, "") != text {
+		t.Fatalf("unexpected data")
+	}
+	if len(got) != len(text)/100 {
+		t.Errorf("unexpected chunk count %d", len(got))
+	}
+
+	got, err = collectStrings(SeqFromReaderWith(
+		io.MultiReader(strings.NewReader("foo"), iotest.ErrReader(errors.New("some error"))),
+		WithReadAhead(),
+	))
+	if want := []string{"foo"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results; got %q want %q", got, want)
+	}
+	if err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSeqFromReaderWithReadAheadOverlaps(t *testing.T) {
+	// secondRead is closed when the second Read call starts,
+	// which must happen while the first chunk is being consumed.
+	secondRead := make(chan struct{})
+	reads := 0
+	r := readerFunc(func(buf []byte) (int, error) {
+		reads++
+		switch reads {
+		case 1:
+			return copy(buf, "one"), nil
+		case 2:
+			close(secondRead)
+			return copy(buf, "two"), nil
+		}
+		return 0, io.EOF
+	})
+	var got []string
+	for data, err := range SeqFromReaderWith(r, WithReadAhead()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) == 0 {
+			select {
+			case <-secondRead:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("read-ahead did not happen")
+			}
+		}
+		got = append(got, string(data))
+	}
+	if want := []string{"one", "two"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results; got %q want %q", got, want)
+	}
+}
+
+type readerFunc func([]byte) (int, error)
+
+func (f readerFunc) Read(buf []byte) (int, error) {
+	return f(buf)
+}
+
+func TestSeqFromReaderWithFinalData(t *testing.T) {
+	readErr := errors.New("read error")
+	for _, readAhead := range []bool{false, true} {
+		t.Run(fmt.Sprint("readAhead=", readAhead), func(t *testing.T) {
+			i := 0
+			r := readerFunc(func(buf []byte) (int, error) {
+				i++
+				if i == 1 {
+					return copy(buf, "hello"), nil
+				}
+				return copy(buf, "world"), readErr
+			})
+			opts := []ReaderOption{WithFinalData()}
+			if readAhead {
+				opts = append(opts, WithReadAhead())
+			}
+			type elem struct {
+				data string
+				err  error
+			}
+			var got []elem
+			for data, err := range SeqFromReaderWith(r, opts...) {
+				got = append(got, elem{string(data), err})
+			}
+			want := []elem{{"hello", nil}, {"world", readErr}}
+			if !slices.Equal(got, want) {
+				t.Errorf("unexpected elements; got %v want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package ioseq
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// RestartableSeq holds a [Seq] that can safely be iterated over more
+// than once, yielding the same data each time. Sequences derived from
+// in-memory data, such as those returned by [SeqFromBytes], are
+// restartable; sequences that read from an [io.Reader] usually are not.
+//
+// Code that needs to replay data, such as an HTTP client following a
+// redirect, can use a RestartableSeq to avoid buffering the data
+// itself.
+type RestartableSeq struct {
+	Seq Seq
+
+	// Size holds the total number of bytes yielded by Seq,
+	// or -1 if that is not known.
+	Size int64
+}
+
+// Restartable returns a [RestartableSeq] that records that seq may be
+// iterated over more than once. It is the caller's responsibility to
+// ensure that this is the case. The size of the data is recorded as
+// unknown; set the Size field if it is known.
+func Restartable(seq Seq) RestartableSeq {
+	return RestartableSeq{
+		Seq:  seq,
+		Size: -1,
+	}
+}
+
+// ErrAlreadyIterated is yielded by a sequence returned from [OnceSeq]
+// when it is iterated over more than once.
+var ErrAlreadyIterated = errors.New("single-use sequence iterated more than once")
+
+// OnceSeq returns a [Seq] that yields the elements of seq the first time
+// it is iterated over, and yields only [ErrAlreadyIterated] on any later
+// iteration. Many sequences, such as those that read from an
+// [io.Reader], can only be consumed once, and iterating over them a
+// second time silently yields no data; OnceSeq turns that mistake into
+// an error. Sequences that genuinely support re-iteration can be marked
+// as such with [Restartable].
+func OnceSeq(seq Seq) Seq {
+	var used atomic.Bool
+	return func(yield func([]byte, error) bool) {
+		if used.Swap(true) {
+			yield(nil, ErrAlreadyIterated)
+			return
+		}
+		seq(yield)
+	}
+}
@@ -0,0 +1,18 @@
+package ioseq
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOnceSeq(t *testing.T) {
+	seq := OnceSeq(SeqFromReader(strings.NewReader("hello"), 10))
+	data, err := ReadAllSeq(seq)
+	if string(data) != "hello" || err != nil {
+		t.Fatalf("unexpected first result %q, %v", data, err)
+	}
+	data, err = ReadAllSeq(seq)
+	if len(data) != 0 || err != ErrAlreadyIterated {
+		t.Fatalf("unexpected second result %q, %v", data, err)
+	}
+}
@@ -0,0 +1,177 @@
+package ioseq
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// RetryPolicy configures the behavior of [RetrySeq].
+type RetryPolicy struct {
+	// MaxRetries holds the maximum number of consecutive
+	// retries that will be made without any data being read.
+	// If it's zero, no retries are made.
+	MaxRetries int
+
+	// InitialDelay holds the delay before the first retry.
+	// The delay doubles for each consecutive retry
+	// without progress.
+	InitialDelay time.Duration
+
+	// MaxDelay holds the maximum delay between retries.
+	// If it's zero, there is no maximum.
+	MaxDelay time.Duration
+
+	// Retryable reports whether the given error is transient
+	// and thus whether the operation should be retried.
+	// If it's nil, Classifier is used instead.
+	Retryable func(err error) bool
+
+	// Classifier is used to decide whether an error is
+	// retryable when Retryable is nil: only errors classified
+	// as [ErrorRetryable] are retried. If both are nil,
+	// [DefaultErrorClassifier] is used.
+	Classifier ErrorClassifier
+
+	// BufSize holds the size of the buffer used to read
+	// from each reader, as for [SeqFromReader].
+	// If it's zero, [DefaultBufferSize] is used.
+	BufSize int
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	switch {
+	case p.Retryable != nil:
+		return p.Retryable(err)
+	case p.Classifier != nil:
+		return p.Classifier.ClassifyError(err) == ErrorRetryable
+	}
+	return DefaultErrorClassifier.ClassifyError(err) == ErrorRetryable
+}
+
+// RetrySeq returns a [Seq] that yields data read from readers returned
+// by open. When an error occurs, either from open or from reading, and
+// policy allows a retry, the reader is closed and after a delay open is
+// called again with the offset of the first byte that has not yet been
+// yielded, so the data continues seamlessly from where it left off.
+//
+// The delay between retries grows exponentially while no progress is
+// being made, and is reset when data is read successfully.
+//
+// RetrySeq is equivalent to RetrySeqContext(context.Background(), open, policy).
+func RetrySeq(open func(offset int64) (io.ReadCloser, error), policy RetryPolicy) Seq {
+	return RetrySeqContext(context.Background(), open, policy)
+}
+
+// RetrySeqContext is like [RetrySeq] except that if ctx is cancelled
+// while waiting to retry, the wait is abandoned and the sequence
+// finishes with ctx.Err(). The context does not affect open or
+// reading; use a context-aware open function for that.
+func RetrySeqContext(ctx context.Context, open func(offset int64) (io.ReadCloser, error), policy RetryPolicy) Seq {
+	bufSize := policy.BufSize
+	if bufSize <= 0 {
+		bufSize = DefaultBufferSize
+	}
+	return func(yield func([]byte, error) bool) {
+		offset := int64(0)
+		retries := 0
+		delay := policy.InitialDelay
+		stopped := false
+		// read opens a reader at the current offset and yields
+		// data from it. It returns whether any data was read
+		// and the error that terminated the reading, if any.
+		read := func() (progress bool, err error) {
+			r, err := open(offset)
+			if err != nil {
+				return false, err
+			}
+			defer r.Close()
+			for data, err := range SeqFromReader(r, bufSize) {
+				if err != nil {
+					return progress, err
+				}
+				if len(data) > 0 {
+					progress = true
+				}
+				if !yield(data, nil) {
+					stopped = true
+					return progress, nil
+				}
+				offset += int64(len(data))
+			}
+			return progress, nil
+		}
+		for {
+			progress, err := read()
+			if err == nil || stopped {
+				return
+			}
+			if progress {
+				retries = 0
+				delay = policy.InitialDelay
+			}
+			if retries >= policy.MaxRetries || !policy.retryable(err) {
+				yield(nil, err)
+				return
+			}
+			retries++
+			if err := sleep(ctx, delay); err != nil {
+				yield(nil, err)
+				return
+			}
+			delay *= 2
+			if policy.MaxDelay > 0 {
+				delay = min(delay, policy.MaxDelay)
+			}
+		}
+	}
+}
+
+// sleep waits for the given duration,
+// returning early with ctx.Err() if ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ResumeSeq adapts a producer that can recover from errors into a
+// [Seq] that follows the usual contract. Unlike an ordinary sequence,
+// seq may continue after yielding an error, for example after a tape
+// or network source has skipped a bad block or re-established a
+// connection, provided that yield returns true; it is up to seq to
+// decide where to continue from.
+//
+// When seq yields an error, onError is called with it. If onError
+// returns true, the error is treated as recovered: it is not passed to
+// the consumer and seq is allowed to continue. Otherwise the error is
+// yielded and the sequence ends.
+//
+// Unlike [RetrySeq], ResumeSeq does not restart the source itself.
+func ResumeSeq(seq Seq, onError func(err error) bool) Seq {
+	return func(yield func([]byte, error) bool) {
+		for data, err := range seq {
+			if err != nil {
+				if onError(err) {
+					continue
+				}
+				yield(nil, err)
+				return
+			}
+			if !yield(data, nil) {
+				return
+			}
+		}
+	}
+}
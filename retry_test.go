@@ -0,0 +1,166 @@
+package ioseq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// flakyOpener returns an open function for RetrySeq that serves
+// content, failing with errTransient after every failAfter bytes
+// read from any one reader.
+func flakyOpener(content string, failAfter int, offsets *[]int64) func(int64) (io.ReadCloser, error) {
+	return func(offset int64) (io.ReadCloser, error) {
+		*offsets = append(*offsets, offset)
+		r := io.MultiReader(
+			io.LimitReader(strings.NewReader(content[offset:]), int64(failAfter)),
+			iotest.ErrReader(errTransient),
+		)
+		if int(offset)+failAfter >= len(content) {
+			r = strings.NewReader(content[offset:])
+		}
+		return io.NopCloser(iotest.OneByteReader(r)), nil
+	}
+}
+
+// errTransient is classified as retryable by DefaultErrorClassifier.
+var errTransient = fmt.Errorf("transient error: %w", io.ErrUnexpectedEOF)
+
+func TestRetrySeq(t *testing.T) {
+	var offsets []int64
+	seq := RetrySeq(flakyOpener("hello, world", 5, &offsets), RetryPolicy{
+		MaxRetries: 1,
+	})
+	got, err := collectStrings(seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(got, ""), "hello, world"; got != want {
+		t.Errorf("unexpected result; got %q want %q", got, want)
+	}
+	if got, want := fmt.Sprint(offsets), "[0 5 10]"; got != want {
+		t.Errorf("unexpected offsets; got %v want %v", got, want)
+	}
+}
+
+func TestRetrySeqGivesUp(t *testing.T) {
+	attempts := 0
+	open := func(offset int64) (io.ReadCloser, error) {
+		attempts++
+		return nil, errTransient
+	}
+	_, err := collectStrings(RetrySeq(open, RetryPolicy{
+		MaxRetries:   3,
+		InitialDelay: time.Millisecond,
+	}))
+	if err != errTransient {
+		t.Errorf("unexpected error %v", err)
+	}
+	if attempts != 4 {
+		t.Errorf("unexpected attempt count; got %d want 4", attempts)
+	}
+}
+
+func TestRetrySeqNotRetryable(t *testing.T) {
+	var offsets []int64
+	seq := RetrySeq(flakyOpener("hello, world", 5, &offsets), RetryPolicy{
+		MaxRetries: 10,
+		Retryable: func(err error) bool {
+			return err != errTransient
+		},
+	})
+	got, err := collectStrings(seq)
+	if err != errTransient {
+		t.Errorf("unexpected error %v", err)
+	}
+	if got, want := strings.Join(got, ""), "hello"; got != want {
+		t.Errorf("unexpected result; got %q want %q", got, want)
+	}
+}
+
+func TestRetrySeqDefaultNotRetryable(t *testing.T) {
+	fatal := errors.New("fatal")
+	attempts := 0
+	open := func(offset int64) (io.ReadCloser, error) {
+		attempts++
+		return nil, fatal
+	}
+	_, err := collectStrings(RetrySeq(open, RetryPolicy{
+		MaxRetries: 3,
+	}))
+	if err != fatal {
+		t.Errorf("unexpected error %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("unexpected attempt count; got %d want 1", attempts)
+	}
+}
+
+func TestRetrySeqContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	open := func(offset int64) (io.ReadCloser, error) {
+		attempts++
+		cancel()
+		return nil, errTransient
+	}
+	start := time.Now()
+	_, err := collectStrings(RetrySeqContext(ctx, open, RetryPolicy{
+		MaxRetries:   3,
+		InitialDelay: time.Hour,
+	}))
+	if err != context.Canceled {
+		t.Errorf("unexpected error %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("unexpected attempt count; got %d want 1", attempts)
+	}
+	if d := time.Since(start); d > time.Minute {
+		t.Errorf("retry delay not cancelled; took %v", d)
+	}
+}
+
+func TestResumeSeq(t *testing.T) {
+	glitch := errors.New("glitch")
+	fatal := errors.New("fatal")
+	// tape yields blocks, reporting a glitch after each one but
+	// continuing if allowed, until the fatal error.
+	tape := func(yield func([]byte, error) bool) {
+		for _, block := range []string{"a", "b", "c"} {
+			if !yield([]byte(block), nil) {
+				return
+			}
+			if !yield(nil, glitch) {
+				return
+			}
+		}
+		yield(nil, fatal)
+	}
+	var recovered []error
+	data, err := ReadAllSeq(ResumeSeq(tape, func(err error) bool {
+		recovered = append(recovered, err)
+		return err == glitch
+	}))
+	if string(data) != "abc" {
+		t.Errorf("unexpected data %q", data)
+	}
+	if err != fatal {
+		t.Errorf("unexpected error %v", err)
+	}
+	if len(recovered) != 4 {
+		t.Errorf("unexpected recovered errors %v", recovered)
+	}
+
+	// When onError returns false, the producer is stopped.
+	data, err = ReadAllSeq(ResumeSeq(tape, func(err error) bool {
+		return false
+	}))
+	if string(data) != "a" || err != glitch {
+		t.Errorf("unexpected result %q, %v", data, err)
+	}
+}
@@ -0,0 +1,143 @@
+package ioseq
+
+import (
+	"bufio"
+	"io"
+)
+
+// Scanner provides an interface similar to [bufio.Scanner] for reading
+// tokens from a [Seq]. Unlike bufio.Scanner, there is no maximum token
+// size: the buffer grows as needed to hold the largest token, so
+// very long lines never cause an error.
+//
+// Tokens are found by the same mechanism as [SplitFuncSeq], so
+// tokens that lie within a single chunk of the sequence are not
+// copied.
+type Scanner struct {
+	seq   Seq
+	split bufio.SplitFunc
+	r     *PullReader
+	token []byte
+	err   error
+}
+
+// NewScanner returns a new Scanner that reads from seq. The split
+// function defaults to [bufio.ScanLines]. The Close method must be
+// called if the caller stops scanning before Scan returns false.
+func NewScanner(seq Seq) *Scanner {
+	return &Scanner{
+		seq:   seq,
+		split: bufio.ScanLines,
+	}
+}
+
+// Split sets the split function for the Scanner.
+// It panics if it is called after scanning has started.
+func (s *Scanner) Split(split bufio.SplitFunc) {
+	if s.r != nil {
+		panic("ioseq: Split called after Scan")
+	}
+	s.split = split
+}
+
+// Scan advances the Scanner to the next token, which will then be
+// available through the Bytes or Text method. It returns false when
+// there are no more tokens, either because the end of the sequence
+// was reached or because of an error, which will be returned by Err.
+func (s *Scanner) Scan() bool {
+	if s.r == nil {
+		s.r = NewPullReader(SplitFuncSeq(s.seq, s.split))
+	}
+	token, err := s.r.Next()
+	if err != nil {
+		s.token = nil
+		if err != io.EOF {
+			s.err = err
+		}
+		s.r.Close()
+		return false
+	}
+	s.token = token
+	return true
+}
+
+// Bytes returns the most recent token generated by a call to Scan.
+// The underlying array may point to data that will be overwritten by
+// a subsequent call to Scan.
+func (s *Scanner) Bytes() []byte {
+	return s.token
+}
+
+// Text returns the most recent token generated by a call
+// to Scan as a newly allocated string.
+func (s *Scanner) Text() string {
+	return string(s.token)
+}
+
+// Err returns the first error that was encountered by the Scanner.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// Close stops the underlying sequence. It is not necessary
+// to call Close after Scan has returned false.
+func (s *Scanner) Close() error {
+	if s.r != nil {
+		s.r.Close()
+	}
+	return nil
+}
+
+// SeqFromScanner returns a [Seq] that yields each token produced by s.
+// If sep is non-empty, it is appended to each token, which is useful
+// for restoring a separator removed by the split function, such as the
+// newline removed by [bufio.ScanLines]. If s encounters an error, it is
+// yielded as the final element.
+func SeqFromScanner(s *bufio.Scanner, sep []byte) Seq {
+	return func(yield func([]byte, error) bool) {
+		var buf []byte
+		for s.Scan() {
+			token := s.Bytes()
+			if len(sep) > 0 {
+				buf = append(append(buf[:0], token...), sep...)
+				token = buf
+			}
+			if !yield(token, nil) {
+				return
+			}
+		}
+		if err := s.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// SeqFromBufioReader returns a [Seq] that yields data directly from
+// the internal buffer of br, using [bufio.Reader.Peek] and
+// [bufio.Reader.Discard], so no additional buffer is allocated and no
+// data is copied.
+//
+// Data is only discarded from br once the consumer has finished with
+// it, so if the consumer stops early, the most recently yielded chunk
+// remains unread in br.
+func SeqFromBufioReader(br *bufio.Reader) Seq {
+	return func(yield func([]byte, error) bool) {
+		for {
+			if br.Buffered() == 0 {
+				// Peek fills the buffer with as much
+				// data as is available.
+				if _, err := br.Peek(1); err != nil {
+					if err != io.EOF {
+						yield(nil, err)
+					}
+					return
+				}
+			}
+			data, _ := br.Peek(br.Buffered())
+			if !yield(data, nil) {
+				return
+			}
+			br.Discard(len(data))
+		}
+	}
+}
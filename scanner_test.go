@@ -0,0 +1,123 @@
+package ioseq
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestScannerLongLines(t *testing.T) {
+	long := strings.Repeat("x", 3*bufio.MaxScanTokenSize)
+	var chunks []string
+	for s := long + "\nshort\n" + long; len(s) > 0; s = s[min(1000, len(s)):] {
+		chunks = append(chunks, s[:min(1000, len(s))])
+	}
+	s := NewScanner(seqOfStrings(chunks...))
+	var got []string
+	for s.Scan() {
+		got = append(got, s.Text())
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{long, "short", long}; !slices.Equal(got, want) {
+		t.Errorf("unexpected tokens (lengths %d)", len(got))
+	}
+}
+
+func TestScannerSplit(t *testing.T) {
+	s := NewScanner(seqOfStrings("one tw", "o  three"))
+	s.Split(bufio.ScanWords)
+	var got []string
+	for s.Scan() {
+		got = append(got, string(s.Bytes()))
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"one", "two", "three"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestScannerError(t *testing.T) {
+	s := NewScanner(ConcatSeqs(seqOfStrings("a\nb"), ErrorSeq(fmt.Errorf("some error"))))
+	var got []string
+	for s.Scan() {
+		got = append(got, s.Text())
+	}
+	// As with bufio.Scanner, the final token is
+	// delivered before the error.
+	if want := []string{"a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if err := s.Err(); err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSeqFromScanner(t *testing.T) {
+	s := bufio.NewScanner(strings.NewReader("one\r\ntwo\nthree"))
+	got, err := collectStrings(SeqFromScanner(s, []byte("\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"one\n", "two\n", "three\n"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+
+	s = bufio.NewScanner(strings.NewReader("one two"))
+	s.Split(bufio.ScanWords)
+	got, err = collectStrings(SeqFromScanner(s, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"one", "two"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestSeqFromBufioReader(t *testing.T) {
+	br := bufio.NewReaderSize(strings.NewReader(strings.Repeat("abcdefgh", 10)), 16)
+	got, err := collectStrings(SeqFromBufioReader(br))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(got, ""), strings.Repeat("abcdefgh", 10); got != want {
+		t.Errorf("unexpected result; got %q want %q", got, want)
+	}
+}
+
+func TestSeqFromBufioReaderEarlyStop(t *testing.T) {
+	br := bufio.NewReaderSize(strings.NewReader("hello, world"), 16)
+	br.ReadByte()
+	for data, err := range SeqFromBufioReader(br) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "ello, world" {
+			t.Errorf("unexpected data %q", data)
+		}
+		break
+	}
+	// The data should still be available.
+	rest, _ := io.ReadAll(br)
+	if got, want := string(rest), "ello, world"; got != want {
+		t.Errorf("unexpected remaining data; got %q want %q", got, want)
+	}
+}
+
+func TestSeqFromBufioReaderError(t *testing.T) {
+	br := bufio.NewReader(io.MultiReader(strings.NewReader("abc"), iotest.ErrReader(fmt.Errorf("some error"))))
+	got, err := collectStrings(SeqFromBufioReader(br))
+	if got, want := strings.Join(got, ""), "abc"; got != want {
+		t.Errorf("unexpected result; got %q want %q", got, want)
+	}
+	if err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package ioseq
+
+import (
+	"bytes"
+)
+
+// IndexSeq returns the offset of the first instance of pattern in the
+// data yielded by seq, or -1 if pattern is not present. Matches that
+// straddle chunk boundaries are found. Iteration stops as soon as a
+// match is found.
+//
+// If seq yields an error before a match is found, IndexSeq returns -1
+// and that error.
+func IndexSeq(seq Seq, pattern []byte) (int64, error) {
+	if len(pattern) == 0 {
+		return 0, nil
+	}
+	// tail holds the trailing bytes of the data seen so far,
+	// up to one fewer than the length of the pattern.
+	keep := len(pattern) - 1
+	tail := make([]byte, 0, keep)
+	var edge []byte
+	off := int64(0)
+	for data, err := range seq {
+		if err != nil {
+			return -1, err
+		}
+		if len(tail) > 0 {
+			edge = append(edge[:0], tail...)
+			edge = append(edge, data[:min(len(data), keep)]...)
+			if i := bytes.Index(edge, pattern); i >= 0 {
+				return off - int64(len(tail)) + int64(i), nil
+			}
+		}
+		if i := bytes.Index(data, pattern); i >= 0 {
+			return off + int64(i), nil
+		}
+		off += int64(len(data))
+		if len(data) >= keep {
+			tail = append(tail[:0], data[len(data)-keep:]...)
+		} else {
+			tail = append(tail, data...)
+			tail = tail[:copy(tail, tail[max(0, len(tail)-keep):])]
+		}
+	}
+	return -1, nil
+}
+
+// ReplaceSeq returns a [Seq] that yields the data from seq with all
+// non-overlapping instances of old replaced by new, including
+// instances that straddle chunk boundaries. The chunk boundaries in
+// the returned sequence do not necessarily correspond to those in seq.
+//
+// At most len(old)-1 bytes are held back between chunks, so memory
+// usage is bounded regardless of the size of the data.
+//
+// ReplaceSeq panics if old is empty.
+func ReplaceSeq(seq Seq, old, new []byte) Seq {
+	if len(old) == 0 {
+		panic("ioseq: empty pattern")
+	}
+	old = bytes.Clone(old)
+	new = bytes.Clone(new)
+	keep := len(old) - 1
+	return func(yield func([]byte, error) bool) {
+		emit := func(data []byte) bool {
+			return len(data) == 0 || yield(data[:len(data):len(data)], nil)
+		}
+		// carry holds data from previous chunks that has not
+		// been yielded yet because it might be the start of a match.
+		// It never contains a complete instance of old.
+		var carry, edge []byte
+		for data, err := range seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if len(carry) > 0 {
+				edge = append(edge[:0], carry...)
+				edge = append(edge, data[:min(len(data), keep)]...)
+				switch i := bytes.Index(edge, old); {
+				case i >= 0 && i < len(carry):
+					// The match starts in the carried-over data.
+					if !emit(carry[:i]) || !emit(new) {
+						return
+					}
+					data = data[i+len(old)-len(carry):]
+				case i < 0 && len(data) < keep:
+					// There's not enough data to decide yet.
+					carry = append(carry, data...)
+					n := max(0, len(carry)-keep)
+					if !emit(carry[:n]) {
+						return
+					}
+					carry = carry[:copy(carry, carry[n:])]
+					continue
+				default:
+					// No match can start in the carried-over data.
+					if !emit(carry) {
+						return
+					}
+				}
+				carry = carry[:0]
+			}
+			for {
+				i := bytes.Index(data, old)
+				if i < 0 {
+					break
+				}
+				if !emit(data[:i]) || !emit(new) {
+					return
+				}
+				data = data[i+len(old):]
+			}
+			n := max(0, len(data)-keep)
+			if !emit(data[:n]) {
+				return
+			}
+			carry = append(carry, data[n:]...)
+		}
+		emit(carry)
+	}
+}
+
+// GrepSeq returns a [Seq] that yields only the lines in seq for which
+// match returns true. As with [LinesSeq], each yielded line includes
+// its terminating newline, if any, but the line passed to match has
+// any trailing "\n" or "\r\n" removed. For example, to filter lines
+// with a regular expression, pass re.Match as the match function.
+func GrepSeq(seq Seq, match func(line []byte) bool) Seq {
+	return FilterSeq(LinesSeq(seq), func(line []byte) bool {
+		return match(dropNewline(line))
+	})
+}
+
+// dropNewline removes any trailing newline from line,
+// in the same way as [bufio.ScanLines].
+func dropNewline(line []byte) []byte {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	return bytes.TrimSuffix(line, []byte("\r"))
+}
@@ -0,0 +1,170 @@
+package ioseq
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var indexSeqTests = []struct {
+	testName string
+	in       []string
+	pattern  string
+	want     int64
+}{{
+	testName: "NotFound",
+	in:       []string{"abc", "def"},
+	pattern:  "xyz",
+	want:     -1,
+}, {
+	testName: "EmptyPattern",
+	in:       []string{"abc"},
+	pattern:  "",
+	want:     0,
+}, {
+	testName: "WithinChunk",
+	in:       []string{"abc", "defgh"},
+	pattern:  "fg",
+	want:     5,
+}, {
+	testName: "Straddling",
+	in:       []string{"abc", "def"},
+	pattern:  "cde",
+	want:     2,
+}, {
+	testName: "StraddlingSeveralChunks",
+	in:       []string{"ab", "c", "", "d", "ef"},
+	pattern:  "bcde",
+	want:     1,
+}, {
+	testName: "FirstOfMany",
+	in:       []string{"xxabxx", "abxx"},
+	pattern:  "ab",
+	want:     2,
+}, {
+	testName: "PartialMatchThenMatch",
+	in:       []string{"aaa", "ab"},
+	pattern:  "aab",
+	want:     2,
+}}
+
+func TestIndexSeq(t *testing.T) {
+	for _, test := range indexSeqTests {
+		t.Run(test.testName, func(t *testing.T) {
+			got, err := IndexSeq(seqOfStrings(test.in...), []byte(test.pattern))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("unexpected index; got %d want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIndexSeqError(t *testing.T) {
+	in := func(yield func([]byte, error) bool) {
+		if !yield([]byte("abc"), nil) {
+			return
+		}
+		yield(nil, fmt.Errorf("some error"))
+	}
+	got, err := IndexSeq(in, []byte("x"))
+	if got != -1 {
+		t.Errorf("unexpected index %d", got)
+	}
+	if err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+var replaceSeqTests = []struct {
+	testName string
+	in       []string
+	old, new string
+}{{
+	testName: "Empty",
+	old:      "x",
+	new:      "y",
+}, {
+	testName: "WithinChunks",
+	in:       []string{"a.b.c", ".d"},
+	old:      ".",
+	new:      "::",
+}, {
+	testName: "Straddling",
+	in:       []string{"hello {", "{name}", "}, {{na", "me}} and {{", "nam"},
+	old:      "{{name}}",
+	new:      "world",
+}, {
+	testName: "ShortChunks",
+	in:       []string{"a", "b", "a", "b", "c", "a", "b", "c", "d"},
+	old:      "abc",
+	new:      "X",
+}, {
+	testName: "RepeatedPrefix",
+	in:       []string{"aaa", "aab", "aa"},
+	old:      "aab",
+	new:      "",
+}, {
+	testName: "ToEmpty",
+	in:       []string{"xxyxx", "yxxx"},
+	old:      "xx",
+	new:      "",
+}}
+
+func TestReplaceSeq(t *testing.T) {
+	for _, test := range replaceSeqTests {
+		t.Run(test.testName, func(t *testing.T) {
+			got, err := collectStrings(ReplaceSeq(seqOfStrings(test.in...), []byte(test.old), []byte(test.new)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := strings.ReplaceAll(strings.Join(test.in, ""), test.old, test.new)
+			if got := strings.Join(got, ""); got != want {
+				t.Errorf("unexpected result; got %q want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestReplaceSeqAllChunkings(t *testing.T) {
+	const text = "the cat sat on the mat with the other cat"
+	for size := 1; size <= len(text); size++ {
+		var chunks []string
+		for s := text; len(s) > 0; s = s[min(size, len(s)):] {
+			chunks = append(chunks, s[:min(size, len(s))])
+		}
+		got, err := collectStrings(ReplaceSeq(seqOfStrings(chunks...), []byte("the "), []byte("a ")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.ReplaceAll(text, "the ", "a ")
+		if got := strings.Join(got, ""); got != want {
+			t.Errorf("chunk size %d: unexpected result; got %q want %q", size, got, want)
+		}
+	}
+}
+
+func TestGrepSeq(t *testing.T) {
+	in := seqOfStrings("INFO start\nERR", "OR bad thing\r\nINFO ok\nERROR", " final")
+	got, err := collectStrings(GrepSeq(in, regexp.MustCompile(`^ERROR .*g$`).Match))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ERROR bad thing\r\n"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	got, err = collectStrings(GrepSeq(in, func(line []byte) bool {
+		return bytes.HasPrefix(line, []byte("ERROR"))
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ERROR bad thing\r\n", "ERROR final"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+}
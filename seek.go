@@ -0,0 +1,182 @@
+package ioseq
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+)
+
+// ReadSeekerFromSeq is like [ReaderFromSeq] except that seq must be
+// restartable: iterating over it more than once must yield the same
+// data each time. This makes it possible for the returned reader to
+// implement [io.Seeker]: seeking backwards restarts the iteration,
+// discarding data up to the new offset, and seeking forwards discards
+// data from the current iteration.
+//
+// Seeking relative to the end requires the size of the data, which is
+// found by iterating over the whole sequence the first time it is
+// needed.
+//
+// Close must be called after the caller is done with the reader.
+func ReadSeekerFromSeq(seq Seq) io.ReadSeekCloser {
+	return &seqReadSeeker{
+		seq:  seq,
+		size: -1,
+	}
+}
+
+type seqReadSeeker struct {
+	seq Seq
+	// r holds the reader for the current iteration, if any.
+	r io.ReadCloser
+	// pos holds the current offset.
+	pos int64
+	// size holds the total size of the data, or -1 if not yet known.
+	size int64
+}
+
+func (r *seqReadSeeker) Read(buf []byte) (int, error) {
+	if r.r == nil {
+		r.r = ReaderFromSeq(SkipSeq(r.seq, r.pos))
+	}
+	n, err := r.r.Read(buf)
+	r.pos += int64(n)
+	return n, err
+}
+
+var errNegativePosition = errors.New("ioseq: seek to negative position")
+
+func (r *seqReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		size, err := r.getSize()
+		if err != nil {
+			return r.pos, err
+		}
+		offset += size
+	default:
+		return r.pos, errors.New("ioseq: invalid whence")
+	}
+	if offset < 0 {
+		return r.pos, errNegativePosition
+	}
+	switch {
+	case offset == r.pos:
+	case offset > r.pos && r.r != nil:
+		// Seeking forward: discard data from the current iteration.
+		if _, err := io.CopyN(io.Discard, r.r, offset-r.pos); err != nil && err != io.EOF {
+			r.r.Close()
+			r.r = nil
+			return r.pos, err
+		}
+	default:
+		// Seeking backward: start again next time we read.
+		if r.r != nil {
+			r.r.Close()
+			r.r = nil
+		}
+	}
+	r.pos = offset
+	return offset, nil
+}
+
+func (r *seqReadSeeker) getSize() (int64, error) {
+	if r.size >= 0 {
+		return r.size, nil
+	}
+	size := int64(0)
+	for data, err := range r.seq {
+		if err != nil {
+			return 0, err
+		}
+		size += int64(len(data))
+	}
+	r.size = size
+	return size, nil
+}
+
+func (r *seqReadSeeker) Close() error {
+	var err error
+	if r.r != nil {
+		err = r.r.Close()
+		r.r = nil
+	}
+	return err
+}
+
+// SeekableFromSeq reads all the data from seq and returns a reader
+// that provides random access to it. Up to memLimit bytes are held in
+// memory; if there is more data than that, all of it is written to a
+// temporary file instead, which is removed when the reader is closed.
+//
+// The returned reader also implements [io.ReaderAt] and has a Size
+// method that returns the total size of the data, as
+// [io.SectionReader] does.
+//
+// If seq fails or the temporary file cannot be written,
+// SeekableFromSeq returns the error.
+func SeekableFromSeq(seq Seq, memLimit int64) (_ io.ReadSeekCloser, err error) {
+	var buf []byte
+	var f *os.File
+	defer func() {
+		if err != nil && f != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	size := int64(0)
+	for data, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		size += int64(len(data))
+		if f == nil {
+			if size <= memLimit {
+				buf = append(buf, data...)
+				continue
+			}
+			// Spill to disk.
+			f, err = os.CreateTemp("", "ioseq-")
+			if err != nil {
+				return nil, err
+			}
+			if _, err := f.Write(buf); err != nil {
+				return nil, err
+			}
+			buf = nil
+		}
+		if _, err := f.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if f == nil {
+		return &seekable{
+			SectionReader: io.NewSectionReader(bytes.NewReader(buf), 0, size),
+		}, nil
+	}
+	return &seekable{
+		SectionReader: io.NewSectionReader(f, 0, size),
+		f:             f,
+	}, nil
+}
+
+type seekable struct {
+	*io.SectionReader
+	f *os.File
+}
+
+func (s *seekable) Close() error {
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	if rerr := os.Remove(s.f.Name()); err == nil {
+		err = rerr
+	}
+	s.f = nil
+	return err
+}
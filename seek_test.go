@@ -0,0 +1,116 @@
+package ioseq
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadSeekerFromSeq(t *testing.T) {
+	const content = "hello, world; this is some content"
+	iterations := 0
+	seq := func(yield func([]byte, error) bool) {
+		iterations++
+		for s := content; len(s) > 0; s = s[min(5, len(s)):] {
+			if !yield([]byte(s[:min(5, len(s))]), nil) {
+				return
+			}
+		}
+	}
+	r := ReadSeekerFromSeq(seq)
+	defer r.Close()
+	// iotest.TestReader exercises seeking in various ways
+	// when the reader implements io.Seeker.
+	if err := iotest.TestReader(r, []byte(content)); err != nil {
+		t.Fatal(err)
+	}
+
+	pos, err := r.Seek(-7, io.SeekEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(len(content) - 7); pos != want {
+		t.Fatalf("unexpected position; got %d want %d", pos, want)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "content"; got != want {
+		t.Errorf("unexpected data; got %q want %q", got, want)
+	}
+	if _, err := r.Seek(-1, io.SeekStart); err == nil {
+		t.Errorf("unexpected success seeking to negative position")
+	}
+	if _, err := r.Seek(7, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	// Seeking forward should not restart the iteration.
+	before := iterations
+	if _, err := r.Seek(2, io.SeekCurrent); err != nil {
+		t.Fatal(err)
+	}
+	data, err = io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(buf)+"|"+string(data), "world|this is some content"; got != want {
+		t.Errorf("unexpected data; got %q want %q", got, want)
+	}
+	if iterations != before {
+		t.Errorf("forward seek restarted the iteration")
+	}
+}
+
+func TestSeekableFromSeq(t *testing.T) {
+	const content = "hello, world; this is some content"
+	for _, memLimit := range []int64{0, 10, int64(len(content))} {
+		t.Run(fmt.Sprint(memLimit), func(t *testing.T) {
+			r, err := SeekableFromSeq(reusingSeq(strings.SplitAfter(content, " ")...), memLimit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := r.(*seekable)
+			if spilled := s.f != nil; spilled != (memLimit < int64(len(content))) {
+				t.Errorf("unexpected spill status %v", spilled)
+			}
+			if err := iotest.TestReader(r, []byte(content)); err != nil {
+				t.Fatal(err)
+			}
+			buf := make([]byte, 5)
+			if _, err := r.(io.ReaderAt).ReadAt(buf, 7); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := string(buf), "world"; got != want {
+				t.Errorf("unexpected ReadAt result; got %q want %q", got, want)
+			}
+			var name string
+			if s.f != nil {
+				name = s.f.Name()
+			}
+			if err := r.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if name != "" {
+				if _, err := os.Stat(name); !os.IsNotExist(err) {
+					t.Errorf("temporary file was not removed")
+				}
+			}
+		})
+	}
+}
+
+func TestSeekableFromSeqError(t *testing.T) {
+	in := ConcatSeqs(seqOfStrings("hello, world"), ErrorSeq(fmt.Errorf("some error")))
+	_, err := SeekableFromSeq(in, 5)
+	if err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
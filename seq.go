@@ -1,10 +1,15 @@
 package ioseq
 
 import (
+	"bufio"
 	"errors"
 	"io"
 	"iter"
+	"runtime"
 	"slices"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
 )
 
 // Seq represents a sequence of byte slices. It's somewhat equivalent to
@@ -19,6 +24,15 @@ import (
 // The sequence always ends at the first error: if there are temporary
 // errors, it's up to the producer to deal with them.
 //
+// Some sources, like [io.Reader], can deliver data together with the
+// error that terminates them. As an opt-in extension to the rules
+// above, the final element of a sequence may hold both data and an
+// error, meaning that the data was produced and then the error
+// occurred. Such sequences must only be passed to consumers that
+// document support for them, such as [ReaderFromSeq] and [CopySeq];
+// [SplitFinalSeq] converts one into a sequence that follows the
+// usual rules.
+//
 // The code ranging over the sequence must not use the slice outside of
 // the loop or across iterations; that is, the receiver owns a slice
 // until that particular iteration ends.
@@ -81,59 +95,374 @@ func (f writerFunc) Write(buf []byte) (int, error) {
 
 // ReaderFromSeq converts an iterator into an io.ReadCloser.
 // Close must be called after the caller is done with the reader.
+// It returns any error from the sequence that the caller
+// has not already seen.
+//
+// If the final element of seq holds both data and an error,
+// the data is read before the error is returned.
+//
+// The returned reader is a [*SeqReader].
 func ReaderFromSeq(seq Seq) io.ReadCloser {
-	return &iterReader{
+	return NewSeqReader(seq)
+}
+
+// NewSeqReader returns a [SeqReader] that reads from seq.
+func NewSeqReader(seq Seq) *SeqReader {
+	r := &SeqReader{
 		seq: seq,
 	}
+	r.trackLeak()
+	return r
 }
 
-type iterReader struct {
+// SeqReader is the reader implementation returned by [ReaderFromSeq].
+// As well as [io.Reader] and [io.Closer], it implements
+// [io.WriterTo], [io.ByteScanner] and [io.RuneScanner].
+//
+// Once the reader has been closed, all its read methods return
+// [ErrClosed]. Close may be called more than once, and may be called
+// concurrently with another method, in which case resources are
+// released when that method returns; other methods must not be called
+// concurrently.
+//
+// The reader holds on to the most recently yielded chunk between
+// calls, but this does not violate the [Seq] contract even when the
+// producer reuses its buffer: the sequence is driven by [iter.Pull2],
+// so the producer remains suspended inside yield until the reader has
+// consumed all of the chunk and asks for the next one. Bytes that
+// must outlive the chunk, such as those saved for UnreadByte or
+// UnreadRune, are copied.
+type SeqReader struct {
+	// state holds the readerBusy and readerClosed flags. It's
+	// manipulated atomically so that the cost of checking it
+	// is low for small reads.
+	state atomic.Int32
+
+	// causeMu guards cause while the reader is being closed.
+	causeMu sync.Mutex
+
 	seq Seq
 
 	next  func() ([]byte, error, bool)
 	close func()
 	err   error
 	data  []byte
+
+	// errReported records whether err has
+	// been returned to the caller.
+	errReported bool
+
+	// cause holds the error passed to CloseWithError.
+	// Writers created by ReaderWithContent return it
+	// when the reader has been closed.
+	cause error
+
+	// cleanup reports the reader as leaked if it is
+	// garbage collected without being closed.
+	cleanup runtime.Cleanup
+
+	// consumed holds the number of bytes returned
+	// to the caller so far.
+	consumed int64
+
+	// pushback holds bytes that have been returned to the reader by
+	// UnreadByte or UnreadRune, or left over after decoding a rune
+	// that spanned chunks. The last pbLen bytes are read before data.
+	pushback [2 * utf8.UTFMax]byte
+	pbLen    int
+
+	// last holds the bytes returned by the most recent call to
+	// ReadByte or ReadRune, so that they can be unread.
+	last     [utf8.UTFMax]byte
+	lastLen  int
+	lastRune bool
+}
+
+const (
+	readerBusy = 1 << iota
+	readerClosed
+)
+
+// enter reports whether the reader is still open,
+// marking it as busy if so.
+func (r *SeqReader) enter() bool {
+	// Only Close can run concurrently with other methods,
+	// so if the reader isn't closed, it must be idle.
+	return r.state.CompareAndSwap(0, readerBusy)
+}
+
+// exit marks the reader as no longer busy, releasing
+// its resources if it was closed in the meantime.
+func (r *SeqReader) exit() {
+	if !r.state.CompareAndSwap(readerBusy, 0) {
+		// Close was called while we were busy,
+		// leaving it to us to release resources.
+		r.state.Store(readerClosed)
+		r.release()
+	}
 }
 
-// WriteTo implements [WriterTo].
-func (r *iterReader) WriteTo(w io.Writer) (int64, error) {
+// WriteTo implements [WriterTo]. Data is written directly from the
+// chunks yielded by the sequence, without copying, even after
+// some data has been read with other methods.
+func (r *SeqReader) WriteTo(w io.Writer) (int64, error) {
+	if !r.enter() {
+		return 0, ErrClosed
+	}
+	defer r.exit()
+	r.lastLen = 0
 	if r.seq != nil {
 		// Read hasn't been called yet, we can just use the
 		// iterator directly, saving the cost of iter.Pull2.
 		n, err := CopySeq(w, r.seq)
+		r.consumed += n
 		// Subsequent reads should return EOF.
 		r.seq = func(func([]byte, error) bool) {}
 		return n, err
 	}
-	return io.Copy(w, r)
+	tot := int64(0)
+	if r.pbLen > 0 {
+		n, err := r.writeData(w, r.pushback[len(r.pushback)-r.pbLen:])
+		r.pbLen -= n
+		tot += int64(n)
+		if err != nil {
+			return tot, err
+		}
+	}
+	for r.fill() {
+		n, err := r.writeData(w, r.data)
+		r.data = r.data[n:]
+		tot += int64(n)
+		if err != nil {
+			return tot, err
+		}
+	}
+	if r.err == io.EOF {
+		return tot, nil
+	}
+	return tot, r.readErr()
+}
+
+// writeData writes data to w, accounting for the bytes
+// that were written.
+func (r *SeqReader) writeData(w io.Writer, data []byte) (int, error) {
+	n, err := w.Write(data)
+	r.consumed += int64(n)
+	if err == nil && n != len(data) {
+		err = io.ErrShortWrite
+	}
+	return n, err
 }
 
-func (r *iterReader) Read(buf []byte) (int, error) {
+// fill makes sure that r.data is non-empty, pulling
+// the next chunk from the sequence if necessary.
+// It reports whether there is data available.
+func (r *SeqReader) fill() bool {
 	if r.seq != nil {
 		r.next, r.close = iter.Pull2(r.seq)
 		// Can't use the fast path in WriteTo any more.
 		r.seq = nil
 	}
-	if r.err != nil {
-		return 0, r.err
-	}
-	if len(r.data) == 0 {
+	// Note: the producer may overwrite r.data as soon as next is
+	// called, so we must only call it when r.data has been
+	// entirely consumed.
+	for len(r.data) == 0 {
+		if r.err != nil {
+			return false
+		}
 		var ok bool
 		r.data, r.err, ok = r.next()
 		if !ok {
 			r.err = io.EOF
 		}
 	}
+	return true
+}
+
+// Read implements [io.Reader].
+func (r *SeqReader) Read(buf []byte) (int, error) {
+	if !r.enter() {
+		return 0, ErrClosed
+	}
+	defer r.exit()
+	r.lastLen = 0
+	if r.pbLen > 0 {
+		n := copy(buf, r.pushback[len(r.pushback)-r.pbLen:])
+		r.pbLen -= n
+		r.consumed += int64(n)
+		return n, nil
+	}
+	if !r.fill() {
+		return 0, r.readErr()
+	}
 	n := copy(buf, r.data)
 	r.data = r.data[n:]
-	if len(r.data) > 0 {
-		return n, nil
+	r.consumed += int64(n)
+	return n, nil
+}
+
+// nextByte returns the next byte from the reader
+// and reports whether there was one.
+func (r *SeqReader) nextByte() (byte, bool) {
+	if r.pbLen > 0 {
+		c := r.pushback[len(r.pushback)-r.pbLen]
+		r.pbLen--
+		r.consumed++
+		return c, true
+	}
+	if !r.fill() {
+		return 0, false
+	}
+	c := r.data[0]
+	r.data = r.data[1:]
+	r.consumed++
+	return c, true
+}
+
+// unget returns buf to the front of the reader.
+func (r *SeqReader) unget(buf []byte) {
+	r.pbLen += len(buf)
+	r.consumed -= int64(len(buf))
+	copy(r.pushback[len(r.pushback)-r.pbLen:], buf)
+}
+
+// ReadByte implements [io.ByteReader].
+func (r *SeqReader) ReadByte() (byte, error) {
+	if !r.enter() {
+		return 0, ErrClosed
+	}
+	defer r.exit()
+	r.lastLen = 0
+	c, ok := r.nextByte()
+	if !ok {
+		return 0, r.readErr()
+	}
+	r.last[0], r.lastLen, r.lastRune = c, 1, false
+	return c, nil
+}
+
+// UnreadByte implements [io.ByteScanner].
+func (r *SeqReader) UnreadByte() error {
+	if !r.enter() {
+		return ErrClosed
+	}
+	defer r.exit()
+	if r.lastLen == 0 {
+		return bufio.ErrInvalidUnreadByte
+	}
+	r.unget(r.last[r.lastLen-1 : r.lastLen])
+	r.lastLen = 0
+	return nil
+}
+
+// ReadRune implements [io.RuneReader].
+func (r *SeqReader) ReadRune() (rune, int, error) {
+	if !r.enter() {
+		return 0, 0, ErrClosed
+	}
+	defer r.exit()
+	r.lastLen = 0
+	if r.pbLen == 0 && r.fill() && utf8.FullRune(r.data) {
+		// Fast path: the rune lies entirely within the current chunk.
+		c, size := utf8.DecodeRune(r.data)
+		copy(r.last[:], r.data[:size])
+		r.data = r.data[size:]
+		r.consumed += int64(size)
+		r.lastLen, r.lastRune = size, true
+		return c, size, nil
+	}
+	var buf [utf8.UTFMax]byte
+	n := 0
+	for n < len(buf) && !utf8.FullRune(buf[:n]) {
+		c, ok := r.nextByte()
+		if !ok {
+			break
+		}
+		buf[n] = c
+		n++
+	}
+	if n == 0 {
+		return 0, 0, r.readErr()
+	}
+	c, size := utf8.DecodeRune(buf[:n])
+	r.unget(buf[size:n])
+	copy(r.last[:], buf[:size])
+	r.lastLen, r.lastRune = size, true
+	return c, size, nil
+}
+
+// UnreadRune implements [io.RuneScanner].
+func (r *SeqReader) UnreadRune() error {
+	if !r.enter() {
+		return ErrClosed
+	}
+	defer r.exit()
+	if r.lastLen == 0 || !r.lastRune {
+		return bufio.ErrInvalidUnreadRune
+	}
+	r.unget(r.last[:r.lastLen])
+	r.lastLen = 0
+	return nil
+}
+
+// readErr returns the error that terminated the
+// sequence, recording that it has been seen.
+func (r *SeqReader) readErr() error {
+	r.errReported = true
+	return r.err
+}
+
+// Close implements [io.Closer]. If the sequence terminated with an
+// error that has already been pulled from it but has not been returned
+// by a read method, Close returns it. Close never asks the sequence
+// for more data; see [SeqReader.Finish] for that.
+//
+// Calls to Close after the first return nil.
+func (r *SeqReader) Close() error {
+	for {
+		state := r.state.Load()
+		if state&readerClosed != 0 {
+			return nil
+		}
+		if !r.state.CompareAndSwap(state, state|readerClosed) {
+			continue
+		}
+		if state&readerBusy != 0 {
+			// The method in progress will call release
+			// when it's done.
+			return nil
+		}
+		return r.release()
+	}
+}
+
+// Finish is like [SeqReader.Close] except that, if the caller has
+// consumed all the data pulled from the sequence so far, it first
+// pulls one more element to find out whether the sequence ended in
+// error. This means that an error immediately following the last of
+// the data is not lost when the caller stops reading before seeing
+// [io.EOF], for example because it knew how much data to expect.
+//
+// Unlike Close, Finish may block until the producer yields its
+// next element, and may cause it to do more work, such as another
+// network read.
+func (r *SeqReader) Finish() error {
+	if r.enter() {
+		if r.close != nil && r.err == nil && len(r.data) == 0 && r.pbLen == 0 {
+			if _, err, ok := r.next(); ok && err != nil {
+				r.err = err
+			}
+		}
+		r.exit()
 	}
-	return n, r.err
+	return r.Close()
 }
 
-func (r *iterReader) Close() error {
+// release releases the resources associated with the
+// reader. It's called exactly once, by whichever of Close
+// or exit observes that the reader is closed and idle.
+func (r *SeqReader) release() error {
+	r.cleanup.Stop()
 	if r.close != nil {
 		r.close()
 		r.close = nil
@@ -141,25 +470,69 @@ func (r *iterReader) Close() error {
 			r.err = io.EOF
 		}
 	}
+	if r.err != nil && r.err != io.EOF && !r.errReported {
+		return r.readErr()
+	}
 	return nil
 }
 
+// CloseWithError is like [SeqReader.Close] except that, when the reader
+// was created by [ReaderWithContent], subsequent writes made by the
+// generating function will return a [*TerminatedError] wrapping err
+// instead of [ErrSequenceTerminated]. This lets the consumer tell the
+// producer why the data is no longer wanted; for example, a consumer
+// that gives up because its context was cancelled can pass ctx.Err().
+// If err is nil, CloseWithError is equivalent to Close.
+//
+// Other producers see the usual termination of the iteration.
+func (r *SeqReader) CloseWithError(err error) error {
+	r.causeMu.Lock()
+	if r.state.Load()&readerClosed == 0 && r.cause == nil {
+		r.cause = err
+	}
+	r.causeMu.Unlock()
+	return r.Close()
+}
+
 // CopySeq is like [io.Copy] but reads over r writing
 // all the data to w. It returns the total number of bytes
-// read.
+// written.
+//
+// As with io.Copy, if w writes fewer bytes than requested
+// without returning an error, CopySeq returns [io.ErrShortWrite].
+//
+// If the final element of r holds both data and an error,
+// the data is written before the error is returned.
+//
+// See [CopySeqCounts] for a way to find out how much data
+// was read as well.
 func CopySeq(w io.Writer, r Seq) (int64, error) {
-	tot := int64(0)
-	for data, err := range r {
-		if err != nil {
-			return tot, err
+	_, written, err := CopySeqCounts(w, r)
+	return written, err
+}
+
+// CopySeqCounts is like [CopySeq] but returns the number of bytes read
+// from r as well as the number of bytes written to w. The two differ
+// only when a write fails or is short, in which case read includes
+// the whole of the chunk that was being written.
+func CopySeqCounts(w io.Writer, r Seq) (read, written int64, err error) {
+	for data, seqErr := range r {
+		if data != nil {
+			read += int64(len(data))
+			n, err := w.Write(data)
+			written += int64(n)
+			if err == nil && n != len(data) {
+				err = io.ErrShortWrite
+			}
+			if err != nil {
+				return read, written, err
+			}
 		}
-		n, err := w.Write(data)
-		tot += int64(n)
-		if err != nil {
-			return tot, err
+		if seqErr != nil {
+			return read, written, seqErr
 		}
 	}
-	return tot, nil
+	return read, written, nil
 }
 
 // SeqWriter returns a [Writer] that operates on the yield
@@ -178,6 +551,9 @@ func CopySeq(w io.Writer, r Seq) (int64, error) {
 //
 // The caller can use the value of *active to find out whether
 // the iterator is still active.
+//
+// The returned Writer implements [SeqWriteCloser], which
+// can be used to end the sequence with an error.
 func SeqWriter(yield func([]byte, error) bool, active *bool) io.Writer {
 	if active == nil {
 		active = new(bool)
@@ -189,46 +565,217 @@ func SeqWriter(yield func([]byte, error) bool, active *bool) io.Writer {
 	}
 }
 
+// SeqWriteCloser is implemented by the writer returned by [SeqWriter]
+// and by [*BufferedSeqWriter]. It gives a producer a way to end the
+// sequence, possibly with an error, without needing to use the yield
+// function directly.
+type SeqWriteCloser interface {
+	io.WriteCloser
+
+	// CloseWithError yields err as the final element of the sequence,
+	// unless the sequence has already been terminated or err is nil.
+	// Subsequent writes return [ErrSequenceTerminated]. If the
+	// sequence had already been terminated by the consumer, the
+	// termination error is returned.
+	//
+	// The iterator function should return soon after calling
+	// CloseWithError.
+	CloseWithError(err error) error
+}
+
 type seqWriter struct {
 	yield  func([]byte, error) bool
 	active *bool
+	// cause, if non-nil, points to the reason the consumer
+	// gave for terminating the sequence.
+	cause *error
+}
+
+// terminated returns the error to return when
+// the sequence has been terminated.
+func (w seqWriter) terminated() error {
+	if w.cause != nil && *w.cause != nil {
+		return &TerminatedError{
+			Cause: *w.cause,
+		}
+	}
+	return ErrSequenceTerminated
 }
 
+// ErrSequenceTerminated is returned by writers created with
+// [SeqWriter] and similar functions when the consumer has
+// stopped iterating over the sequence.
 var ErrSequenceTerminated = errors.New("sequence terminated")
 
+// TerminatedError is returned instead of [ErrSequenceTerminated] when
+// the consumer gave a reason for terminating the sequence, for example
+// by calling [SeqReader.CloseWithError]. It wraps both
+// ErrSequenceTerminated and the reason, so a producer can use
+// [errors.Is] with ErrSequenceTerminated to check for termination
+// and [errors.As] to distinguish a consumer that failed, or whose
+// context was cancelled, from one that just finished early.
+type TerminatedError struct {
+	Cause error
+}
+
+func (e *TerminatedError) Error() string {
+	return "sequence terminated: " + e.Cause.Error()
+}
+
+func (e *TerminatedError) Unwrap() []error {
+	return []error{ErrSequenceTerminated, e.Cause}
+}
+
+// ErrClosed is returned when reading from a [SeqReader]
+// that has been closed.
+var ErrClosed = errors.New("read from closed reader")
+
 func (w seqWriter) Write(buf []byte) (int, error) {
 	if !*w.active {
-		return 0, ErrSequenceTerminated
+		return 0, w.terminated()
 	}
 	if !w.yield(slices.Clip(buf), nil) {
 		*w.active = false
-		return 0, ErrSequenceTerminated
+		return 0, w.terminated()
 	}
 	return len(buf), nil
 }
 
+// WriteString implements [io.StringWriter]. The string's bytes are
+// yielded without copying; this is safe because consumers must not
+// modify yielded slices.
+func (w seqWriter) WriteString(str string) (int, error) {
+	return w.Write(stringBytes(str))
+}
+
+// Close implements [SeqWriteCloser.Close]
+// by calling CloseWithError(nil).
+func (w seqWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+// CloseWithError implements [SeqWriteCloser.CloseWithError].
+func (w seqWriter) CloseWithError(err error) error {
+	if !*w.active {
+		return w.terminated()
+	}
+	*w.active = false
+	if err != nil {
+		w.yield(nil, err)
+	}
+	return nil
+}
+
+// ReadFrom implements [io.ReaderFrom] by yielding data directly from
+// the chunks produced by r. If r implements [io.WriterTo], no buffer
+// is needed at all.
+func (w seqWriter) ReadFrom(r io.Reader) (int64, error) {
+	if !*w.active {
+		return 0, w.terminated()
+	}
+	n := int64(0)
+	for data, err := range SeqFromReader(r, DefaultBufferSize) {
+		if err != nil {
+			return n, err
+		}
+		if !w.yield(slices.Clip(data), nil) {
+			*w.active = false
+			return n, w.terminated()
+		}
+		n += int64(len(data))
+	}
+	return n, nil
+}
+
 // PipeSeqThrough returns a Seq that iterates over the data written
 // by the function f to its argument Writer. The Writer implementation
 // that it returns will be written with the data read from seq.
 //
 // In other words, data read from seq will be "piped through" f,
 // resulting in a new Seq.
+//
+// The writer is always closed, even when the copy from seq fails, so
+// that any resources it holds are released. If both the copy and
+// Close fail, both errors are yielded, joined as by [errors.Join], so
+// a copy error is never masked by an error from Close.
+// See [SeqWithContext] for how to add cancellation.
 func PipeSeqThrough[W io.WriteCloser](seq Seq, f func(w io.Writer) W) Seq {
 	return func(yield func([]byte, error) bool) {
-		send := func(w io.WriteCloser, seq Seq) error {
-			if _, err := CopySeq(w, seq); err != nil {
-				return err
-			}
-			return w.Close()
-		}
 		active := true
 		w := f(SeqWriter(yield, &active))
-		if err := send(w, seq); err != nil && active {
+		_, err := CopySeq(w, seq)
+		err = joinErrors(err, w.Close())
+		if err != nil && active {
+			yield(nil, err)
+		}
+	}
+}
+
+// PipeSeqThroughReader is like [PipeSeqThrough] but for filters that
+// take the form of a reader wrapper, such as [gzip.NewReader]. The
+// returned [Seq] yields the data read from the reader returned by f,
+// which is passed a reader that reads from seq. If f returns an
+// error, that error is yielded.
+//
+// If the reader returned by f implements [io.WriterTo], its data is
+// yielded without copying; otherwise it is read into a buffer of
+// [DefaultBufferSize] bytes.
+//
+// When the iteration finishes, the reader returned by f is closed if
+// it implements [io.Closer], and any error from seq that has not
+// already been seen by the filter (for example, an error after the
+// end of a compressed stream) is yielded. If closing the filter fails
+// too, both errors are yielded, joined as by [errors.Join]; likewise
+// when f fails.
+func PipeSeqThroughReader(seq Seq, f func(io.Reader) (io.Reader, error)) Seq {
+	return func(yield func([]byte, error) bool) {
+		src := NewSeqReader(seq)
+		r, err := f(src)
+		if err != nil {
+			yield(nil, joinErrors(err, src.Close()))
+			return
+		}
+		for data, err := range SeqFromReader(r, DefaultBufferSize) {
+			if !yield(data, err) || err != nil {
+				closeFilter(r)
+				src.Close()
+				return
+			}
+		}
+		// The filter has finished, so check whether seq
+		// has anything further to say, such as an error
+		// after the end of a compressed stream.
+		if err := joinErrors(closeFilter(r), src.Finish()); err != nil {
 			yield(nil, err)
 		}
 	}
 }
 
+// DefaultBufferSize holds the buffer size used when
+// reading from a reader that does not implement
+// [io.WriterTo] and no explicit size is given.
+const DefaultBufferSize = 32 * 1024
+
+// joinErrors is like [errors.Join] except that when only one
+// of the errors is non-nil, it is returned unchanged.
+func joinErrors(err1, err2 error) error {
+	switch {
+	case err1 == nil:
+		return err2
+	case err2 == nil:
+		return err1
+	}
+	return errors.Join(err1, err2)
+}
+
+// closeFilter closes r if it implements [io.Closer].
+func closeFilter(r io.Reader) error {
+	if c, ok := r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 // PipeThrough calls f; all data written by f to its argument writer
 // will be made available on the returned ReadCloser; all data read from
 // f will be written to the writer implementation returned by f.
@@ -244,11 +791,23 @@ func PipeThrough[W io.WriteCloser](r io.Reader, f func(io.Writer) W, bufSize int
 // ReaderWithContent returns a [Reader] that calls the given function to generate the
 // data to be read. If the function returns an error, that error will
 // be returned from the reader.
+//
+// The returned reader is a [*SeqReader]; if it is closed with
+// [SeqReader.CloseWithError], writes made by generate will
+// return the error passed to it.
 func ReaderWithContent(generate func(w io.Writer) error) io.ReadCloser {
-	return ReaderFromSeq(func(yield func([]byte, error) bool) {
+	r := &SeqReader{}
+	r.seq = func(yield func([]byte, error) bool) {
 		active := true
-		if err := generate(SeqWriter(yield, &active)); err != nil && active {
+		w := seqWriter{
+			yield:  yield,
+			active: &active,
+			cause:  &r.cause,
+		}
+		if err := generate(w); err != nil && active {
 			yield(nil, err)
 		}
-	})
+	}
+	r.trackLeak()
+	return r
 }
@@ -1,12 +1,16 @@
 package ioseq
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
 	"slices"
 	"strings"
 	"testing"
 	"testing/iotest"
+	"time"
 )
 
 func TestReaderFromSeqEarlyClose(t *testing.T) {
@@ -204,3 +208,554 @@ func TestSeqWriterClipsSlice(t *testing.T) {
 		_ = append(data, 'X')
 	}
 }
+
+func TestReaderFromSeqByteAndRuneReader(t *testing.T) {
+	// "é" is two bytes and "世" three; split them across chunks.
+	r := ReaderFromSeq(seqOfStrings("a\xc3", "\xa9\xe4", "\xb8", "\x96\xffz"))
+	defer r.Close()
+	rs := r.(io.RuneScanner)
+
+	var got []rune
+	for {
+		c, _, err := rs.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, c)
+		if c == 'é' {
+			// Check that we can unread a rune
+			// that spanned chunks.
+			if err := rs.UnreadRune(); err != nil {
+				t.Fatal(err)
+			}
+			if err := rs.UnreadRune(); err == nil {
+				t.Fatalf("unexpected success of second UnreadRune")
+			}
+			c, size, err := rs.ReadRune()
+			if c != 'é' || size != 2 || err != nil {
+				t.Fatalf("unexpected rune after UnreadRune: %q, %d, %v", c, size, err)
+			}
+		}
+	}
+	if got, want := string(got), "aé世�z"; got != want {
+		t.Errorf("unexpected runes; got %q want %q", got, want)
+	}
+
+	r = ReaderFromSeq(seqOfStrings("ab", "c"))
+	defer r.Close()
+	bs := r.(io.ByteScanner)
+	c, err := bs.ReadByte()
+	if c != 'a' || err != nil {
+		t.Fatalf("unexpected ReadByte result %q, %v", c, err)
+	}
+	if err := bs.UnreadByte(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "abc"; got != want {
+		t.Errorf("unexpected data; got %q want %q", got, want)
+	}
+	if err := bs.UnreadByte(); err == nil {
+		t.Errorf("unexpected success of UnreadByte after Read")
+	}
+}
+
+func TestReaderFromSeqCloseReportsError(t *testing.T) {
+	seqErr := errors.New("some error")
+	input := func(yield func([]byte, error) bool) {
+		if !yield([]byte("hello"), nil) {
+			return
+		}
+		yield(nil, seqErr)
+	}
+
+	// Read exactly the data, then finish without seeing the error.
+	sr := NewSeqReader(input)
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(sr, buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := sr.Finish(); err != seqErr {
+		t.Fatalf("unexpected error from Finish; got %v want %v", err, seqErr)
+	}
+
+	// Close does not pull the error from the sequence.
+	r := ReaderFromSeq(input)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("unexpected error from Close: %v", err)
+	}
+
+	// When the error has already been returned by Read,
+	// Close does not return it again.
+	r = ReaderFromSeq(input)
+	if _, err := io.ReadAll(r); err != seqErr {
+		t.Fatalf("unexpected error from ReadAll; got %v want %v", err, seqErr)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("unexpected error from Close: %v", err)
+	}
+
+	// When data remains unread, Close does not look further.
+	r = ReaderFromSeq(input)
+	if _, err := r.Read(make([]byte, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("unexpected error from Close: %v", err)
+	}
+}
+
+func TestReaderFromSeqCloseDoesNotBlock(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+	r := ReaderFromSeq(func(yield func([]byte, error) bool) {
+		if !yield([]byte("a"), nil) {
+			return
+		}
+		<-unblock
+		yield([]byte("b"), nil)
+	})
+	if _, err := r.Read(make([]byte, 1)); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Close()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error from Close: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked waiting for the sequence")
+	}
+}
+
+func TestReaderWithContentCloseWithError(t *testing.T) {
+	closeErr := errors.New("no more, thanks")
+	var writeErr error
+	r := ReaderWithContent(func(w io.Writer) error {
+		for {
+			if _, err := io.WriteString(w, "data"); err != nil {
+				writeErr = err
+				return err
+			}
+		}
+	})
+	if _, err := r.Read(make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.(*SeqReader).CloseWithError(closeErr); err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(writeErr, closeErr) || !errors.Is(writeErr, ErrSequenceTerminated) {
+		t.Fatalf("unexpected write error %v", writeErr)
+	}
+	var terr *TerminatedError
+	if !errors.As(writeErr, &terr) || terr.Cause != closeErr {
+		t.Fatalf("write error does not hold cause: %#v", writeErr)
+	}
+	if got, want := writeErr.Error(), "sequence terminated: no more, thanks"; got != want {
+		t.Errorf("unexpected error message; got %q want %q", got, want)
+	}
+}
+
+func TestReaderFromSeqClosed(t *testing.T) {
+	r := ReaderFromSeq(seqOfStrings("hello"))
+	if _, err := r.Read(make([]byte, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("unexpected error from second Close: %v", err)
+	}
+	if _, err := r.Read(make([]byte, 2)); err != ErrClosed {
+		t.Fatalf("unexpected error from Read; got %v want %v", err, ErrClosed)
+	}
+	if _, err := r.(io.WriterTo).WriteTo(io.Discard); err != ErrClosed {
+		t.Fatalf("unexpected error from WriteTo; got %v want %v", err, ErrClosed)
+	}
+	if _, err := r.(io.ByteReader).ReadByte(); err != ErrClosed {
+		t.Fatalf("unexpected error from ReadByte; got %v want %v", err, ErrClosed)
+	}
+
+	// Closing before reading means the sequence is never started.
+	r = ReaderFromSeq(func(yield func([]byte, error) bool) {
+		t.Errorf("sequence unexpectedly started")
+	})
+	r.Close()
+	if _, err := io.ReadAll(r); err != ErrClosed {
+		t.Fatalf("unexpected error from ReadAll; got %v want %v", err, ErrClosed)
+	}
+}
+
+func TestReaderFromSeqCloseDuringRead(t *testing.T) {
+	started := make(chan struct{})
+	proceed := make(chan struct{})
+	stopped := make(chan struct{})
+	r := ReaderFromSeq(func(yield func([]byte, error) bool) {
+		defer close(stopped)
+		close(started)
+		<-proceed
+		for yield([]byte("data"), nil) {
+		}
+	})
+	readDone := make(chan error)
+	go func() {
+		_, err := r.Read(make([]byte, 10))
+		readDone <- err
+	}()
+	<-started
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	close(proceed)
+	if err := <-readDone; err != nil {
+		t.Fatal(err)
+	}
+	// The sequence must be stopped when the Read returns.
+	<-stopped
+	if _, err := r.Read(make([]byte, 10)); err != ErrClosed {
+		t.Fatalf("unexpected error from Read; got %v want %v", err, ErrClosed)
+	}
+}
+
+func TestSeqWriterReadFrom(t *testing.T) {
+	collect := func(r io.Reader) ([]string, int64, error) {
+		var got []string
+		var n int64
+		var copyErr error
+		seq := func(yield func([]byte, error) bool) {
+			n, copyErr = io.Copy(SeqWriter(yield, nil), r)
+		}
+		for data := range seq {
+			got = append(got, string(data))
+		}
+		return got, n, copyErr
+	}
+	// A strings.Reader implements WriterTo, so
+	// its content is yielded as a single chunk.
+	got, n, err := collect(strings.NewReader("hello world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"hello world"}; !slices.Equal(got, want) || n != 11 {
+		t.Errorf("unexpected results; got %q (%d bytes) want %q", got, n, want)
+	}
+
+	got, n, err = collect(iotest.OneByteReader(strings.NewReader("abc")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(got, want) || n != 3 {
+		t.Errorf("unexpected results; got %q (%d bytes) want %q", got, n, want)
+	}
+
+	_, _, err = collect(iotest.ErrReader(errors.New("read error")))
+	if err == nil || err.Error() != "read error" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSeqWriterWriteString(t *testing.T) {
+	seq := func(yield func([]byte, error) bool) {
+		w := SeqWriter(yield, nil)
+		io.WriteString(w, "hello")
+		io.WriteString(w, "world")
+	}
+	var got []string
+	for data := range seq {
+		got = append(got, string(data))
+		if cap(data) != len(data) {
+			t.Errorf("string data not clipped")
+		}
+	}
+	if want := []string{"hello", "world"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results; got %q want %q", got, want)
+	}
+	// The number of allocations should not depend
+	// on the number of strings written.
+	allocs := func(n int) float64 {
+		seq := func(yield func([]byte, error) bool) {
+			w := SeqWriter(yield, nil)
+			for range n {
+				io.WriteString(w, "hello")
+			}
+		}
+		return testing.AllocsPerRun(100, func() {
+			for range seq {
+			}
+		})
+	}
+	if a1, a10 := allocs(1), allocs(10); a10 != a1 {
+		t.Errorf("WriteString allocates; %v allocs for 1 write, %v for 10", a1, a10)
+	}
+}
+
+func TestSeqWriterCloseWithError(t *testing.T) {
+	seqErr := errors.New("some error")
+	var writeErr, closeErr error
+	seq := func(yield func([]byte, error) bool) {
+		w := SeqWriter(yield, nil).(SeqWriteCloser)
+		io.WriteString(w, "hello")
+		w.CloseWithError(seqErr)
+		_, writeErr = io.WriteString(w, "more")
+		closeErr = w.Close()
+	}
+	got, err := collectStrings(seq)
+	if err != seqErr {
+		t.Fatalf("unexpected error; got %v want %v", err, seqErr)
+	}
+	if want := []string{"hello"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results; got %q want %q", got, want)
+	}
+	if writeErr != ErrSequenceTerminated {
+		t.Errorf("unexpected write error; got %v want %v", writeErr, ErrSequenceTerminated)
+	}
+	if closeErr != ErrSequenceTerminated {
+		t.Errorf("unexpected close error; got %v want %v", closeErr, ErrSequenceTerminated)
+	}
+}
+
+func TestPipeSeqThroughReader(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	io.WriteString(zw, strings.Repeat("hello world\n", 1000))
+	zw.Close()
+
+	// Feed the compressed data in small chunks.
+	var chunks []string
+	for c := range slices.Chunk(compressed.Bytes(), 10) {
+		chunks = append(chunks, string(c))
+	}
+	seq := PipeSeqThroughReader(seqOfStrings(chunks...), func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	})
+	data, err := ReadAllSeq(seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), strings.Repeat("hello world\n", 1000); got != want {
+		t.Errorf("unexpected data (len %d)", len(data))
+	}
+
+	// An error from f is yielded.
+	seq = PipeSeqThroughReader(seqOfStrings("not gzip data"), func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	})
+	if _, err := ReadAllSeq(seq); err != gzip.ErrHeader {
+		t.Errorf("unexpected error; got %v want %v", err, gzip.ErrHeader)
+	}
+
+	// An error after the end of the compressed data is yielded.
+	seqErr := errors.New("trailing error")
+	seq = PipeSeqThroughReader(ConcatSeqs(SeqFromBytes(compressed.Bytes()), ErrorSeq(seqErr)), func(r io.Reader) (io.Reader, error) {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		zr.Multistream(false)
+		return zr, nil
+	})
+	if _, err := ReadAllSeq(seq); err != seqErr {
+		t.Errorf("unexpected error; got %v want %v", err, seqErr)
+	}
+
+	// When closing the filter fails too, both errors are yielded.
+	closeErr := errors.New("close error")
+	seq = PipeSeqThroughReader(ConcatSeqs(SeqFromBytes(compressed.Bytes()), ErrorSeq(seqErr)), func(r io.Reader) (io.Reader, error) {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		zr.Multistream(false)
+		return failingCloser{zr, closeErr}, nil
+	})
+	if _, err := ReadAllSeq(seq); !errors.Is(err, seqErr) || !errors.Is(err, closeErr) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestPipeSeqThroughCloseError(t *testing.T) {
+	seqErr := errors.New("seq error")
+	closeErr := errors.New("close error")
+	seq := PipeSeqThrough(ConcatSeqs(seqOfStrings("a"), ErrorSeq(seqErr)), func(w io.Writer) io.WriteCloser {
+		return failingWriteCloser{w, closeErr}
+	})
+	data, err := ReadAllSeq(seq)
+	if !errors.Is(err, seqErr) || !errors.Is(err, closeErr) {
+		t.Errorf("unexpected error %v", err)
+	}
+	if string(data) != "a" {
+		t.Errorf("unexpected data %q", data)
+	}
+}
+
+type failingWriteCloser struct {
+	io.Writer
+	err error
+}
+
+func (w failingWriteCloser) Close() error {
+	return w.err
+}
+
+type failingCloser struct {
+	io.Reader
+	err error
+}
+
+func (r failingCloser) Close() error {
+	return r.err
+}
+
+func TestReaderFromSeqWriteToAfterRead(t *testing.T) {
+	chunk := []byte("header:body part one")
+	seqErr := errors.New("some error")
+	input := func(yield func([]byte, error) bool) {
+		if !yield(chunk, nil) {
+			return
+		}
+		if !yield([]byte(", part two"), nil) {
+			return
+		}
+		yield(nil, seqErr)
+	}
+	r := ReaderFromSeq(input)
+	defer r.Close()
+	buf := make([]byte, 6)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.(io.ByteScanner).UnreadByte(); err == nil {
+		t.Fatalf("unexpected success of UnreadByte after Read")
+	}
+	c, err := r.(io.ByteReader).ReadByte()
+	if err != nil || c != ':' {
+		t.Fatalf("unexpected ReadByte result %q, %v", c, err)
+	}
+	if err := r.(io.ByteScanner).UnreadByte(); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	w := writerFunc(func(data []byte) (int, error) {
+		got = append(got, string(data))
+		if len(got) == 2 && &data[0] != &chunk[7] {
+			t.Errorf("data was copied")
+		}
+		return len(data), nil
+	})
+	n, err := io.Copy(w, r)
+	if err != seqErr {
+		t.Errorf("unexpected error; got %v want %v", err, seqErr)
+	}
+	if want := []string{":", "body part one", ", part two"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected writes; got %q want %q", got, want)
+	}
+	if n != 24 {
+		t.Errorf("unexpected count %d", n)
+	}
+}
+
+// scribblingSeq returns a Seq that yields each of the given strings
+// from the same buffer, overwriting the buffer with garbage as soon
+// as each yield returns, as a producer that reuses its buffer might.
+func scribblingSeq(ss ...string) Seq {
+	return func(yield func([]byte, error) bool) {
+		buf := make([]byte, 64)
+		for _, s := range ss {
+			data := buf[:copy(buf, s)]
+			ok := yield(data, nil)
+			for i := range buf {
+				buf[i] = '#'
+			}
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+func TestReaderFromSeqReusedBuffer(t *testing.T) {
+	input := []string{"hello, ", "w", "orld", "éè"[:3], "éè"[3:] + "!"}
+	want := strings.Join(input, "")
+	t.Run("Read", func(t *testing.T) {
+		r := ReaderFromSeq(scribblingSeq(input...))
+		defer r.Close()
+		got, err := io.ReadAll(iotest.OneByteReader(r))
+		if err != nil || string(got) != want {
+			t.Errorf("unexpected result %q, %v", got, err)
+		}
+	})
+	t.Run("ReadRune", func(t *testing.T) {
+		r := ReaderFromSeq(scribblingSeq(input...)).(io.RuneScanner)
+		defer r.(io.Closer).Close()
+		var got []rune
+		for {
+			c, _, err := r.ReadRune()
+			if err != nil {
+				break
+			}
+			// Unread and read again, to check that the
+			// saved bytes are not overwritten.
+			if err := r.UnreadRune(); err != nil {
+				t.Fatal(err)
+			}
+			c1, _, _ := r.ReadRune()
+			if c1 != c {
+				t.Fatalf("reread rune %q does not match %q", c1, c)
+			}
+			got = append(got, c)
+		}
+		if string(got) != want {
+			t.Errorf("unexpected result %q", string(got))
+		}
+	})
+	t.Run("WriteTo", func(t *testing.T) {
+		r := ReaderFromSeq(scribblingSeq(input...))
+		defer r.Close()
+		buf := make([]byte, 3)
+		io.ReadFull(r, buf)
+		var rest strings.Builder
+		if _, err := io.Copy(&rest, r); err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf) + rest.String(); got != want {
+			t.Errorf("unexpected result %q", got)
+		}
+	})
+}
+
+func TestFinalDataConsumers(t *testing.T) {
+	seqErr := errors.New("some error")
+	var buf bytes.Buffer
+	n, err := CopySeq(&buf, finalDataSeq(seqErr))
+	if buf.String() != "helloworld" || n != 10 || err != seqErr {
+		t.Errorf("unexpected CopySeq result %q, %d, %v", buf.String(), n, err)
+	}
+	r := ReaderFromSeq(finalDataSeq(seqErr))
+	defer r.Close()
+	data, err := io.ReadAll(iotest.OneByteReader(r))
+	if string(data) != "helloworld" || err != seqErr {
+		t.Errorf("unexpected ReadAll result %q, %v", data, err)
+	}
+	r = ReaderFromSeq(finalDataSeq(seqErr))
+	defer r.Close()
+	buf.Reset()
+	n, err = io.Copy(&buf, r)
+	if buf.String() != "helloworld" || n != 10 || err != seqErr {
+		t.Errorf("unexpected io.Copy result %q, %d, %v", buf.String(), n, err)
+	}
+}
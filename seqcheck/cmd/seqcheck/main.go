@@ -0,0 +1,16 @@
+// The seqcheck command reports misuse of byte sequences.
+// It can be run directly or by go vet:
+//
+//	go vet -vettool=$(which seqcheck) ./...
+//
+// See [github.com/rogpeppe/ioseq/seqcheck] for details.
+package main
+
+import (
+	"github.com/rogpeppe/ioseq/seqcheck"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(seqcheck.Analyzer)
+}
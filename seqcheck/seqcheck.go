@@ -0,0 +1,263 @@
+// Package seqcheck defines an analyzer that reports common misuses of
+// byte sequences of the form iter.Seq2[[]byte, error], as used by
+// package [github.com/rogpeppe/ioseq].
+//
+// It lives in its own module so that the ioseq module itself has no
+// dependencies outside the standard library.
+package seqcheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const doc = `report misuse of byte sequences
+
+The seqcheck analyzer reports the following mistakes in code that
+produces or consumes sequences of type iter.Seq2[[]byte, error]:
+
+- calling a yield function of type func([]byte, error) bool and
+  ignoring its result, other than immediately before returning;
+- retaining a slice yielded by a range-over-func loop beyond the
+  current iteration, by assigning it to a variable declared outside
+  the loop, storing it in a field, map, slice or channel, or
+  appending it to a slice of slices;
+- modifying a slice yielded by a range-over-func loop.
+
+Some sequences, such as those returned by ioseq.CloneSeq, yield
+slices that the consumer owns; retaining those is reported too, as
+the analyzer cannot tell them apart from other sequences.`
+
+// Analyzer reports misuse of byte sequences.
+var Analyzer = &analysis.Analyzer{
+	Name:     "seqcheck",
+	Doc:      doc,
+	URL:      "https://pkg.go.dev/github.com/rogpeppe/ioseq/seqcheck",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	// Find all the yield functions: parameters of type
+	// func([]byte, error) bool.
+	yields := make(map[types.Object]bool)
+	insp.Preorder([]ast.Node{(*ast.FuncType)(nil)}, func(n ast.Node) {
+		for _, field := range n.(*ast.FuncType).Params.List {
+			for _, name := range field.Names {
+				if obj := pass.TypesInfo.Defs[name]; obj != nil && isYieldType(obj.Type()) {
+					yields[obj] = true
+				}
+			}
+		}
+	})
+	insp.WithStack([]ast.Node{(*ast.ExprStmt)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		call, ok := n.(*ast.ExprStmt).X.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+		if !ok || !yields[pass.TypesInfo.Uses[id]] {
+			return true
+		}
+		if !isTerminal(stack, func(n ast.Node) bool { return callsYield(pass, yields, n) }) {
+			pass.ReportRangef(call, "result of %s ignored: the sequence must stop when it returns false", id.Name)
+		}
+		return true
+	})
+	insp.Preorder([]ast.Node{(*ast.RangeStmt)(nil)}, func(n ast.Node) {
+		checkRange(pass, n.(*ast.RangeStmt))
+	})
+	return nil, nil
+}
+
+// isYieldType reports whether t is func([]byte, error) bool.
+func isYieldType(t types.Type) bool {
+	sig, ok := t.Underlying().(*types.Signature)
+	if !ok || sig.Params().Len() != 2 || sig.Results().Len() != 1 {
+		return false
+	}
+	return isByteSlice(sig.Params().At(0).Type()) &&
+		isError(sig.Params().At(1).Type()) &&
+		types.Identical(sig.Results().At(0).Type(), types.Typ[types.Bool])
+}
+
+// isSeqType reports whether t is func(func([]byte, error) bool).
+func isSeqType(t types.Type) bool {
+	sig, ok := t.Underlying().(*types.Signature)
+	if !ok || sig.Params().Len() != 1 || sig.Results().Len() != 0 {
+		return false
+	}
+	return isYieldType(sig.Params().At(0).Type())
+}
+
+func isByteSlice(t types.Type) bool {
+	s, ok := t.Underlying().(*types.Slice)
+	return ok && types.Identical(s.Elem(), types.Typ[types.Byte])
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+// isTerminal reports whether the statement at the top of stack is
+// followed only by a return from the enclosing function, possibly
+// after some statements that don't call yield (as reported by
+// callsYield), so that ignoring the result of a call in it does no
+// harm.
+func isTerminal(stack []ast.Node, callsYield func(ast.Node) bool) bool {
+	for i := len(stack) - 1; i > 0; i-- {
+		stmt, parent := stack[i], stack[i-1]
+		var list []ast.Stmt
+		switch parent := parent.(type) {
+		case *ast.BlockStmt:
+			list = parent.List
+		case *ast.CaseClause:
+			list = parent.Body
+		case *ast.CommClause:
+			list = parent.Body
+		case *ast.IfStmt, *ast.LabeledStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+			continue
+		case *ast.FuncLit, *ast.FuncDecl:
+			return true
+		default:
+			// Loops and anything else we don't understand.
+			return false
+		}
+		j := indexOf(list, stmt)
+		switch {
+		case j < 0:
+			return false
+		case j+1 < len(list):
+			rest := list[j+1:]
+			if _, ok := rest[len(rest)-1].(*ast.ReturnStmt); !ok {
+				return false
+			}
+			for _, stmt := range rest {
+				if callsYield(stmt) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// callsYield reports whether n contains a call to any of the given
+// yield functions.
+func callsYield(pass *analysis.Pass, yields map[types.Object]bool, n ast.Node) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if id := funcIdent(call.Fun); id != nil && yields[pass.TypesInfo.Uses[id]] {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+func indexOf(list []ast.Stmt, n ast.Node) int {
+	for i, stmt := range list {
+		if stmt == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkRange checks that the body of a range-over-func loop over a
+// byte sequence neither retains nor modifies the yielded slice.
+func checkRange(pass *analysis.Pass, rng *ast.RangeStmt) {
+	key, ok := rng.Key.(*ast.Ident)
+	if !ok || rng.Tok != token.DEFINE || !isSeqType(pass.TypesInfo.TypeOf(rng.X)) {
+		return
+	}
+	obj := pass.TypesInfo.Defs[key]
+	if obj == nil {
+		return
+	}
+	refers := func(e ast.Expr) bool {
+		e = ast.Unparen(e)
+		if s, ok := e.(*ast.SliceExpr); ok {
+			e = ast.Unparen(s.X)
+		}
+		id, ok := e.(*ast.Ident)
+		return ok && pass.TypesInfo.Uses[id] == obj
+	}
+	// local reports whether e is the loop variable itself or
+	// a variable declared inside the loop body.
+	local := func(e ast.Expr) bool {
+		id, ok := e.(*ast.Ident)
+		if !ok {
+			return false
+		}
+		if id.Name == "_" {
+			return true
+		}
+		v := pass.TypesInfo.ObjectOf(id)
+		return v == obj || v != nil && v.Pos() >= rng.Body.Pos() && v.Pos() < rng.Body.End()
+	}
+	modified := func(e ast.Expr) bool {
+		ix, ok := ast.Unparen(e).(*ast.IndexExpr)
+		return ok && refers(ix.X)
+	}
+	ast.Inspect(rng.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if modified(lhs) {
+					pass.ReportRangef(lhs, "yielded slice %s modified: sequence data must be treated as read-only", key.Name)
+				}
+				if len(n.Lhs) == len(n.Rhs) && refers(n.Rhs[i]) && !local(lhs) {
+					pass.ReportRangef(n.Rhs[i], "yielded slice %s retained beyond the loop iteration: copy it with bytes.Clone", key.Name)
+				}
+			}
+		case *ast.IncDecStmt:
+			if modified(n.X) {
+				pass.ReportRangef(n.X, "yielded slice %s modified: sequence data must be treated as read-only", key.Name)
+			}
+		case *ast.SendStmt:
+			if refers(n.Value) {
+				pass.ReportRangef(n.Value, "yielded slice %s retained beyond the loop iteration: copy it with bytes.Clone", key.Name)
+			}
+		case *ast.CallExpr:
+			b, ok := pass.TypesInfo.Uses[funcIdent(n.Fun)].(*types.Builtin)
+			if !ok || len(n.Args) == 0 {
+				break
+			}
+			switch b.Name() {
+			case "append":
+				if n.Ellipsis.IsValid() {
+					break
+				}
+				for _, arg := range n.Args[1:] {
+					if refers(arg) {
+						pass.ReportRangef(arg, "yielded slice %s retained beyond the loop iteration: copy it with bytes.Clone", key.Name)
+					}
+				}
+			case "copy", "clear":
+				if refers(n.Args[0]) {
+					pass.ReportRangef(n.Args[0], "yielded slice %s modified: sequence data must be treated as read-only", key.Name)
+				}
+			}
+		}
+		return true
+	})
+}
+
+func funcIdent(e ast.Expr) *ast.Ident {
+	id, _ := ast.Unparen(e).(*ast.Ident)
+	return id
+}
@@ -0,0 +1,12 @@
+package seqcheck_test
+
+import (
+	"testing"
+
+	"github.com/rogpeppe/ioseq/seqcheck"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), seqcheck.Analyzer, "a")
+}
@@ -0,0 +1,89 @@
+package a
+
+import (
+	"bytes"
+	"iter"
+)
+
+type Seq = iter.Seq2[[]byte, error]
+
+func goodProducer(yield func([]byte, error) bool) {
+	if !yield([]byte("a"), nil) {
+		return
+	}
+	for range 3 {
+		if !yield([]byte("b"), nil) {
+			return
+		}
+	}
+	yield(nil, nil)
+}
+
+func errorProducer(err error) Seq {
+	return func(yield func([]byte, error) bool) {
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		switch {
+		case err == nil:
+			yield([]byte("x"), nil)
+		}
+	}
+}
+
+func badProducer(yield func([]byte, error) bool) {
+	yield([]byte("a"), nil) // want `result of yield ignored: the sequence must stop when it returns false`
+	for range 3 {
+		yield([]byte("b"), nil) // want `result of yield ignored`
+	}
+	if true {
+		yield([]byte("c"), nil) // want `result of yield ignored`
+	}
+	yield(nil, nil)
+}
+
+func cleanupProducer(yield func([]byte, error) bool, release func()) {
+	for range 3 {
+		if true {
+			yield([]byte("a"), nil)
+			release()
+			return
+		}
+		yield([]byte("b"), nil) // want `result of yield ignored`
+		yield(nil, nil)
+		return
+	}
+}
+
+type T struct {
+	data []byte
+}
+
+func consumers(seq Seq, ch chan []byte, t *T, m map[int][]byte) {
+	var last []byte
+	var all [][]byte
+	var flat []byte
+	for data, err := range seq {
+		if err != nil {
+			return
+		}
+		d := data
+		data = data[1:]
+		_ = d
+		c := bytes.Clone(data)
+		last = c
+		flat = append(flat, data...)
+		last = data                 // want `yielded slice data retained beyond the loop iteration: copy it with bytes.Clone`
+		all = append(all, data)     // want `yielded slice data retained`
+		all = append(all, data[1:]) // want `yielded slice data retained`
+		ch <- data                  // want `yielded slice data retained`
+		t.data = data               // want `yielded slice data retained`
+		m[0] = data                 // want `yielded slice data retained`
+		data[0] = 'x'               // want `yielded slice data modified: sequence data must be treated as read-only`
+		data[0]++                   // want `yielded slice data modified`
+		copy(data, "x")             // want `yielded slice data modified`
+		clear(data)                 // want `yielded slice data modified`
+	}
+	_, _, _ = last, all, flat
+}
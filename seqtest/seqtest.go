@@ -0,0 +1,137 @@
+// Package seqtest implements utilities for testing [ioseq.Seq]
+// producers.
+package seqtest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"iter"
+	"testing"
+	"testing/iotest"
+
+	"github.com/rogpeppe/ioseq"
+)
+
+// TestSeq checks that seq yields exactly the data in want, without
+// error, when consumed in a variety of ways: ranging over the whole
+// sequence, stopping after each element in turn, pulling from it with
+// [iter.Pull2] and reading from it with [ioseq.ReaderFromSeq] using a
+// number of different read sizes (including the checks made by
+// [iotest.TestReader]).
+//
+// Throughout, seq is checked for compliance with the [ioseq.Seq]
+// contract as by [ioseq.ValidateSeq]. Any failures are reported with
+// t.Errorf. Because seq is iterated over several times, it must
+// produce the same data each time.
+func TestSeq(t testing.TB, seq ioseq.Seq, want []byte) {
+	t.Helper()
+	n := 0
+	check(t, "full iteration", func() error {
+		var got []byte
+		for data, err := range ioseq.ValidateSeq(seq) {
+			if err != nil {
+				return fmt.Errorf("unexpected error after %d bytes: %v", len(got), err)
+			}
+			got = append(got, data...)
+			n++
+		}
+		return checkContent(got, want)
+	})
+	for i := range n {
+		check(t, fmt.Sprintf("stop after %d elements", i+1), func() error {
+			var got []byte
+			count := 0
+			for data, err := range ioseq.ValidateSeq(seq) {
+				if err != nil {
+					return fmt.Errorf("unexpected error: %v", err)
+				}
+				got = append(got, data...)
+				count++
+				if count > i {
+					break
+				}
+			}
+			if !bytes.HasPrefix(want, got) {
+				return fmt.Errorf("unexpected content %q; want prefix of %q", got, want)
+			}
+			return nil
+		})
+	}
+	check(t, "pull", func() error {
+		next, stop := iter.Pull2(ioseq.ValidateSeq(seq))
+		defer stop()
+		data, err, ok := next()
+		if !ok {
+			if len(want) > 0 {
+				return fmt.Errorf("sequence is empty; want %q", want)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unexpected error: %v", err)
+		}
+		if !bytes.HasPrefix(want, data) {
+			return fmt.Errorf("unexpected content %q; want prefix of %q", data, want)
+		}
+		return nil
+	})
+	check(t, "reader", func() error {
+		r := ioseq.ReaderFromSeq(ioseq.ValidateSeq(seq))
+		defer r.Close()
+		return iotest.TestReader(r, want)
+	})
+	for _, size := range []int{1, 3, 7, 4093} {
+		check(t, fmt.Sprintf("reader with %d byte reads", size), func() error {
+			r := ioseq.ReaderFromSeq(ioseq.ValidateSeq(seq))
+			got, err := readAll(r, size)
+			if closeErr := r.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("unexpected error after %d bytes: %v", len(got), err)
+			}
+			return checkContent(got, want)
+		})
+	}
+}
+
+// check calls f, reporting any error it returns, or any panic
+// (such as a contract violation), as a test failure.
+func check(t testing.TB, name string, f func() error) {
+	t.Helper()
+	err := func() (err error) {
+		defer func() {
+			if e := recover(); e != nil {
+				err = fmt.Errorf("panic: %v", e)
+			}
+		}()
+		return f()
+	}()
+	if err != nil {
+		t.Errorf("%s: %v", name, err)
+	}
+}
+
+func checkContent(got, want []byte) error {
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("unexpected content; got %q want %q", got, want)
+	}
+	return nil
+}
+
+// readAll reads all of r using a buffer of the given size.
+func readAll(r io.Reader, size int) ([]byte, error) {
+	var got []byte
+	buf := make([]byte, size)
+	for {
+		n, err := r.Read(buf)
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			return got, nil
+		}
+		if err != nil {
+			return got, err
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package seqtest_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rogpeppe/ioseq"
+	"github.com/rogpeppe/ioseq/seqtest"
+)
+
+func TestTestSeqOK(t *testing.T) {
+	content := strings.Repeat("hello, world\n", 1000)
+	seqtest.TestSeq(t, ioseq.LazySeq(func() (ioseq.Seq, error) {
+		// Hide the WriteTo method so that the data is
+		// read in 100 byte chunks.
+		r := struct{ io.Reader }{strings.NewReader(content)}
+		return ioseq.SeqFromReader(r, 100), nil
+	}), []byte(content))
+	seqtest.TestSeq(t, ioseq.LinesSeq(ioseq.SeqFromString(content)), []byte(content))
+	seqtest.TestSeq(t, ioseq.EmptySeq(), nil)
+}
+
+var testSeqFailureTests = []struct {
+	testName string
+	seq      ioseq.Seq
+	want     string
+	wantErr  string
+}{{
+	testName: "WrongContent",
+	seq:      ioseq.SeqFromString("hello"),
+	want:     "goodbye",
+	wantErr:  `full iteration: unexpected content; got "hello" want "goodbye"`,
+}, {
+	testName: "Error",
+	seq:      ioseq.ErrorSeq(fmt.Errorf("oops")),
+	want:     "",
+	wantErr:  `full iteration: unexpected error after 0 bytes: oops`,
+}, {
+	testName: "IgnoresStop",
+	seq: func(yield func([]byte, error) bool) {
+		yield([]byte("a"), nil)
+		yield([]byte("b"), nil)
+	},
+	want:    "ab",
+	wantErr: `stop after 1 elements: panic: ioseq: sequence contract violation at element 1: yield called after yield returned false`,
+}}
+
+func TestTestSeqFailure(t *testing.T) {
+	for _, test := range testSeqFailureTests {
+		t.Run(test.testName, func(t *testing.T) {
+			rt := &recordingT{TB: t}
+			seqtest.TestSeq(rt, test.seq, []byte(test.want))
+			if len(rt.errors) == 0 {
+				t.Fatalf("no errors reported")
+			}
+			if rt.errors[0] != test.wantErr {
+				t.Errorf("unexpected first error; got %q want %q", rt.errors[0], test.wantErr)
+			}
+		})
+	}
+}
+
+// recordingT records errors reported with Errorf rather
+// than failing the test.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Errorf(f string, a ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(f, a...))
+}
+
+func (t *recordingT) Helper() {}
@@ -0,0 +1,99 @@
+package ioseq
+
+import (
+	"errors"
+	"io"
+)
+
+// SizedSeq holds a [Seq] together with the total number of bytes
+// it is expected to yield. A [Seq] is just a function, so it cannot
+// carry its size itself; SizedSeq makes it possible to pass the size
+// along with the sequence to code that can make use of it, such as
+// an HTTP client that needs to set Content-Length.
+type SizedSeq struct {
+	Seq  Seq
+	Size int64
+}
+
+// WithSize returns a [SizedSeq] that records that seq will
+// yield exactly size bytes. It is the caller's responsibility
+// to ensure that this is the case; [ExpectSize] can be used
+// to check it.
+func WithSize(seq Seq, size int64) SizedSeq {
+	return SizedSeq{
+		Seq:  seq,
+		Size: size,
+	}
+}
+
+// SizedReader is implemented by the reader returned by
+// [ReaderFromSizedSeq].
+type SizedReader interface {
+	io.ReadCloser
+
+	// Size returns the total number of bytes in the underlying
+	// sequence. It is not affected by reads.
+	Size() int64
+
+	// Len returns the number of bytes not yet read.
+	Len() int
+}
+
+// ReaderFromSizedSeq is like [ReaderFromSeq] except that the
+// returned reader also reports the size of the data.
+func ReaderFromSizedSeq(s SizedSeq) SizedReader {
+	return &sizedReader{
+		SeqReader: NewSeqReader(s.Seq),
+		size:      s.Size,
+	}
+}
+
+type sizedReader struct {
+	*SeqReader
+	size int64
+}
+
+// Size implements [SizedReader.Size].
+func (r *sizedReader) Size() int64 {
+	return r.size
+}
+
+// Len implements [SizedReader.Len].
+func (r *sizedReader) Len() int {
+	return int(max(0, r.size-r.consumed))
+}
+
+// ErrSizeExceeded is yielded by the sequence returned by [ExpectSize]
+// when the underlying sequence holds more data than expected.
+var ErrSizeExceeded = errors.New("sequence exceeds expected size")
+
+// ExpectSize returns a [Seq] that yields the data from seq, checking
+// that there are exactly n bytes of it. If seq ends early, the
+// returned sequence yields [io.ErrUnexpectedEOF]; if seq holds more
+// than n bytes, the first n bytes are yielded followed by
+// [ErrSizeExceeded]. Errors from seq are passed through unchanged.
+func ExpectSize(seq Seq, n int64) Seq {
+	return func(yield func([]byte, error) bool) {
+		remain := n
+		for data, err := range seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if int64(len(data)) > remain {
+				if remain > 0 && !yield(data[:remain], nil) {
+					return
+				}
+				yield(nil, ErrSizeExceeded)
+				return
+			}
+			if !yield(data, nil) {
+				return
+			}
+			remain -= int64(len(data))
+		}
+		if remain > 0 {
+			yield(nil, io.ErrUnexpectedEOF)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package ioseq
+
+import (
+	"io"
+	"testing"
+)
+
+func TestReaderFromSizedSeq(t *testing.T) {
+	r := ReaderFromSizedSeq(WithSize(seqOfStrings("hello", " ", "world"), 11))
+	defer r.Close()
+	if got, want := r.Size(), int64(11); got != want {
+		t.Fatalf("unexpected size; got %d want %d", got, want)
+	}
+	buf := make([]byte, 3)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Len(), 8; got != want {
+		t.Fatalf("unexpected length after read; got %d want %d", got, want)
+	}
+	if _, err := r.(io.ByteReader).ReadByte(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.(io.ByteScanner).UnreadByte(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Len(), 8; got != want {
+		t.Fatalf("unexpected length after UnreadByte; got %d want %d", got, want)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "lo world"; got != want {
+		t.Fatalf("unexpected data; got %q want %q", got, want)
+	}
+	if got, want := r.Len(), 0; got != want {
+		t.Fatalf("unexpected length at EOF; got %d want %d", got, want)
+	}
+	if got, want := r.Size(), int64(11); got != want {
+		t.Fatalf("unexpected size at EOF; got %d want %d", got, want)
+	}
+}
+
+var expectSizeTests = []struct {
+	testName string
+	input    []string
+	n        int64
+	want     string
+	wantErr  error
+}{{
+	testName: "Exact",
+	input:    []string{"hello", " ", "world"},
+	n:        11,
+	want:     "hello world",
+}, {
+	testName: "Short",
+	input:    []string{"hello", " ", "world"},
+	n:        12,
+	want:     "hello world",
+	wantErr:  io.ErrUnexpectedEOF,
+}, {
+	testName: "LongWithinChunk",
+	input:    []string{"hello", " ", "world"},
+	n:        8,
+	want:     "hello wo",
+	wantErr:  ErrSizeExceeded,
+}, {
+	testName: "LongAtChunkBoundary",
+	input:    []string{"hello", " ", "world"},
+	n:        6,
+	want:     "hello ",
+	wantErr:  ErrSizeExceeded,
+}, {
+	testName: "Empty",
+	n:        0,
+}, {
+	testName: "ZeroButNot",
+	input:    []string{"x"},
+	n:        0,
+	wantErr:  ErrSizeExceeded,
+}}
+
+func TestExpectSize(t *testing.T) {
+	for _, test := range expectSizeTests {
+		t.Run(test.testName, func(t *testing.T) {
+			data, err := ReadAllSeq(ExpectSize(seqOfStrings(test.input...), test.n))
+			if string(data) != test.want {
+				t.Errorf("unexpected data; got %q want %q", data, test.want)
+			}
+			if err != test.wantErr {
+				t.Errorf("unexpected error; got %v want %v", err, test.wantErr)
+			}
+		})
+	}
+}
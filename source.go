@@ -0,0 +1,57 @@
+package ioseq
+
+// LazySeq returns a [Seq] that calls open each time it is iterated
+// over and yields the elements of the sequence it returns. If open
+// returns an error, that error is yielded instead. This makes it
+// possible to construct a pipeline without acquiring resources, such
+// as open files or network connections, until they are needed.
+func LazySeq(open func() (Seq, error)) Seq {
+	return func(yield func([]byte, error) bool) {
+		seq, err := open()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for data, err := range seq {
+			if !yield(data, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// SeqFromBytes returns a [Seq] that yields b as a single chunk,
+// or nothing if b is empty. As usual, consumers must not modify
+// the yielded slice.
+func SeqFromBytes(b []byte) Seq {
+	return func(yield func([]byte, error) bool) {
+		if len(b) > 0 {
+			yield(b, nil)
+		}
+	}
+}
+
+// SeqFromString returns a [Seq] that yields s as a single chunk,
+// or nothing if s is empty.
+func SeqFromString(s string) Seq {
+	return func(yield func([]byte, error) bool) {
+		if len(s) > 0 {
+			yield([]byte(s), nil)
+		}
+	}
+}
+
+// ErrorSeq returns a [Seq] that yields err and nothing else.
+// If err is nil, the sequence is empty.
+func ErrorSeq(err error) Seq {
+	return func(yield func([]byte, error) bool) {
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// EmptySeq returns a [Seq] that yields nothing.
+func EmptySeq() Seq {
+	return func(yield func([]byte, error) bool) {}
+}
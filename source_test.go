@@ -0,0 +1,86 @@
+package ioseq
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestLazySeq(t *testing.T) {
+	opened := 0
+	seq := LazySeq(func() (Seq, error) {
+		opened++
+		return seqOfStrings("a", "b"), nil
+	})
+	if opened != 0 {
+		t.Fatalf("open called too early")
+	}
+	got, err := collectStrings(seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if opened != 1 {
+		t.Errorf("unexpected open count %d", opened)
+	}
+}
+
+func TestLazySeqOpenError(t *testing.T) {
+	seq := LazySeq(func() (Seq, error) {
+		return nil, fmt.Errorf("cannot open")
+	})
+	got, err := collectStrings(seq)
+	if len(got) != 0 {
+		t.Errorf("unexpected results %q", got)
+	}
+	if err == nil || err.Error() != "cannot open" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestLiteralSeqs(t *testing.T) {
+	someErr := fmt.Errorf("some error")
+	tests := []struct {
+		testName string
+		seq      Seq
+		want     []string
+		wantErr  error
+	}{{
+		testName: "Bytes",
+		seq:      SeqFromBytes([]byte("hello")),
+		want:     []string{"hello"},
+	}, {
+		testName: "EmptyBytes",
+		seq:      SeqFromBytes(nil),
+	}, {
+		testName: "String",
+		seq:      SeqFromString("hello"),
+		want:     []string{"hello"},
+	}, {
+		testName: "EmptyString",
+		seq:      SeqFromString(""),
+	}, {
+		testName: "Error",
+		seq:      ErrorSeq(someErr),
+		wantErr:  someErr,
+	}, {
+		testName: "NilError",
+		seq:      ErrorSeq(nil),
+	}, {
+		testName: "Empty",
+		seq:      EmptySeq(),
+	}}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			got, err := collectStrings(test.seq)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("unexpected results;\ngot %q\nwant %q", got, test.want)
+			}
+			if err != test.wantErr {
+				t.Errorf("unexpected error; got %v want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package ioseq
+
+import (
+	"bufio"
+	"bytes"
+)
+
+// LinesSeq returns a [Seq] that yields the same data as seq, but
+// re-sliced so that each element holds exactly one line, including its
+// terminating newline if any. The final line may not end in a newline,
+// and if seq fails, any partial line is yielded before the error.
+//
+// Lines that lie entirely within one chunk of seq are yielded without
+// copying; only lines that span chunk boundaries are buffered.
+func LinesSeq(seq Seq) Seq {
+	return splitSeq(seq, []byte("\n"), true)
+}
+
+// SplitSeq returns a [Seq] that yields the records in seq that are
+// terminated by delim, with the delimiter removed. The final record
+// need not be terminated by delim; if the data ends with delim, no
+// empty final record is produced. A delimiter may straddle chunk
+// boundaries in seq.
+//
+// Records that lie entirely within one chunk of seq are yielded
+// without copying; otherwise only the partial record is buffered.
+// If seq fails, any partial record is yielded before the error.
+//
+// SplitSeq panics if delim is empty.
+func SplitSeq(seq Seq, delim []byte) Seq {
+	return splitSeq(seq, bytes.Clone(delim), false)
+}
+
+// SplitFuncSeq returns a [Seq] that yields the tokens produced by
+// calling split on the data in seq, following the same rules as
+// [bufio.Scanner]. For example, SplitFuncSeq(seq, bufio.ScanWords)
+// yields each space-separated word in seq.
+//
+// Unlike bufio.Scanner, there is no maximum token size: data is
+// buffered for as long as split requests more of it.
+// Tokens that lie entirely within a chunk of seq are passed to split
+// (and usually yielded) without copying.
+//
+// If split returns an error, it is yielded and the sequence ends;
+// [bufio.ErrFinalToken] ends the sequence without error. If seq
+// fails, split is called with atEOF set to deliver any final token
+// before the error is yielded, as bufio.Scanner does.
+func SplitFuncSeq(seq Seq, split bufio.SplitFunc) Seq {
+	return func(yield func([]byte, error) bool) {
+		// scan yields all the tokens it can find in data, and
+		// returns the unconsumed remainder and whether
+		// the iteration should continue.
+		scan := func(data []byte, atEOF bool) ([]byte, bool) {
+			empties := 0
+			for len(data) > 0 || atEOF {
+				advance, token, err := split(data, atEOF)
+				if err != nil {
+					if err == bufio.ErrFinalToken {
+						if token != nil {
+							yield(token, nil)
+						}
+					} else {
+						yield(nil, err)
+					}
+					return nil, false
+				}
+				if advance < 0 {
+					yield(nil, bufio.ErrNegativeAdvance)
+					return nil, false
+				}
+				if advance > len(data) {
+					yield(nil, bufio.ErrAdvanceTooFar)
+					return nil, false
+				}
+				data = data[advance:]
+				if token == nil {
+					if advance == 0 {
+						break
+					}
+					continue
+				}
+				if advance > 0 {
+					empties = 0
+				} else if empties++; empties > maxConsecutiveEmptyTokens {
+					panic("ioseq: too many empty tokens without progressing")
+				}
+				if !yield(token, nil) {
+					return nil, false
+				}
+			}
+			return data, true
+		}
+		var buf []byte
+		for data, err := range seq {
+			if err != nil {
+				// As with bufio.Scanner, deliver any final
+				// token before reporting the error.
+				if _, ok := scan(buf, true); ok {
+					yield(nil, err)
+				}
+				return
+			}
+			if len(buf) > 0 {
+				buf = append(buf, data...)
+				data = buf
+			}
+			rest, ok := scan(data, false)
+			if !ok {
+				return
+			}
+			// Note: rest may alias buf, but append copies
+			// correctly in that case.
+			buf = append(buf[:0], rest...)
+		}
+		scan(buf, true)
+	}
+}
+
+// maxConsecutiveEmptyTokens mirrors the limit used by bufio.Scanner
+// to guard against split functions that never advance.
+const maxConsecutiveEmptyTokens = 100
+
+func splitSeq(seq Seq, delim []byte, keepDelim bool) Seq {
+	if len(delim) == 0 {
+		panic("ioseq: empty delimiter")
+	}
+	return func(yield func([]byte, error) bool) {
+		var buf []byte
+		for data, err := range seq {
+			if err != nil {
+				// Deliver any partial record before
+				// reporting the error.
+				if len(buf) > 0 && !yield(buf, nil) {
+					return
+				}
+				yield(nil, err)
+				return
+			}
+			if len(buf) > 0 {
+				// We've got a partial record from previous chunks;
+				// find out where it ends, taking care to find a
+				// delimiter that straddles the chunk boundary.
+				i := indexJoined(buf, data, delim)
+				if i < 0 {
+					buf = append(buf, data...)
+					continue
+				}
+				end := i + len(delim)
+				used := end - len(buf)
+				buf = append(buf, data[:used]...)
+				if !keepDelim {
+					end = i
+				}
+				if !yield(buf[:end], nil) {
+					return
+				}
+				buf = buf[:0]
+				data = data[used:]
+			}
+			for len(data) > 0 {
+				i := bytes.Index(data, delim)
+				if i < 0 {
+					buf = append(buf, data...)
+					break
+				}
+				end := i + len(delim)
+				rec := data[:end]
+				if !keepDelim {
+					rec = data[:i]
+				}
+				if !yield(rec, nil) {
+					return
+				}
+				data = data[end:]
+			}
+		}
+		if len(buf) > 0 {
+			yield(buf, nil)
+		}
+	}
+}
+
+// indexJoined returns the index of the first instance of delim in the
+// concatenation of buf and data, or -1 if there is none. It assumes
+// that buf does not itself contain delim.
+func indexJoined(buf, data, delim []byte) int {
+	if len(delim) > 1 {
+		// Check for a delimiter straddling the boundary.
+		start := max(0, len(buf)-len(delim)+1)
+		edge := make([]byte, 0, 2*len(delim))
+		edge = append(edge, buf[start:]...)
+		edge = append(edge, data[:min(len(data), len(delim)-1)]...)
+		if i := bytes.Index(edge, delim); i >= 0 {
+			return start + i
+		}
+	}
+	if i := bytes.Index(data, delim); i >= 0 {
+		return len(buf) + i
+	}
+	return -1
+}
@@ -0,0 +1,255 @@
+package ioseq
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var linesSeqTests = []struct {
+	testName string
+	in       []string
+	want     []string
+}{{
+	testName: "Empty",
+	in:       nil,
+	want:     nil,
+}, {
+	testName: "SingleChunk",
+	in:       []string{"one\ntwo\nthree\n"},
+	want:     []string{"one\n", "two\n", "three\n"},
+}, {
+	testName: "NoFinalNewline",
+	in:       []string{"one\ntwo"},
+	want:     []string{"one\n", "two"},
+}, {
+	testName: "SpanningChunks",
+	in:       []string{"o", "ne\ntw", "", "o\nthr", "ee"},
+	want:     []string{"one\n", "two\n", "three"},
+}, {
+	testName: "EmptyLines",
+	in:       []string{"\n\n", "\n"},
+	want:     []string{"\n", "\n", "\n"},
+}}
+
+func TestLinesSeq(t *testing.T) {
+	for _, test := range linesSeqTests {
+		t.Run(test.testName, func(t *testing.T) {
+			got, err := collectStrings(LinesSeq(seqOfStrings(test.in...)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("unexpected results;\ngot %q\nwant %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLinesSeqError(t *testing.T) {
+	in := func(yield func([]byte, error) bool) {
+		if !yield([]byte("one\ntw"), nil) {
+			return
+		}
+		yield(nil, fmt.Errorf("some error"))
+	}
+	got, err := collectStrings(LinesSeq(in))
+	if want := []string{"one\n", "tw"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSplitFuncSeqError(t *testing.T) {
+	in := ConcatSeqs(seqOfStrings("one two", " thr"), ErrorSeq(fmt.Errorf("some error")))
+	got, err := collectStrings(SplitFuncSeq(in, bufio.ScanWords))
+	if want := []string{"one", "two", "thr"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestLinesSeqEarlyStop(t *testing.T) {
+	for line := range LinesSeq(seqOfStrings("one\ntwo\n")) {
+		if got, want := string(line), "one\n"; got != want {
+			t.Fatalf("unexpected line; got %q want %q", got, want)
+		}
+		break
+	}
+}
+
+var splitSeqTests = []struct {
+	testName string
+	in       []string
+	delim    string
+	want     []string
+}{{
+	testName: "Empty",
+	delim:    "\x00",
+	want:     nil,
+}, {
+	testName: "SingleByteDelim",
+	in:       []string{"a\x00bc\x00", "\x00d"},
+	delim:    "\x00",
+	want:     []string{"a", "bc", "", "d"},
+}, {
+	testName: "StraddlingDelim",
+	in:       []string{"head1\r\n\r", "\nhead2\r", "\n", "\r", "\nrest"},
+	delim:    "\r\n\r\n",
+	want:     []string{"head1", "head2", "rest"},
+}, {
+	testName: "PartialDelimInRecord",
+	in:       []string{"a\r\n", "b\r\n\r\n"},
+	delim:    "\r\n\r\n",
+	want:     []string{"a\r\nb"},
+}, {
+	testName: "OverlappingDelimPrefix",
+	in:       []string{"xaa", "ab"},
+	delim:    "aab",
+	want:     []string{"xa"},
+}, {
+	testName: "DelimInSingleChunk",
+	in:       []string{"ab--cd--", "ef"},
+	delim:    "--",
+	want:     []string{"ab", "cd", "ef"},
+}}
+
+func TestSplitSeq(t *testing.T) {
+	for _, test := range splitSeqTests {
+		t.Run(test.testName, func(t *testing.T) {
+			got, err := collectStrings(SplitSeq(seqOfStrings(test.in...), []byte(test.delim)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("unexpected results;\ngot %q\nwant %q", got, test.want)
+			}
+		})
+	}
+}
+
+var splitFuncSeqTests = []struct {
+	testName string
+	in       []string
+	split    bufio.SplitFunc
+	want     []string
+	wantErr  string
+}{{
+	testName: "Words",
+	in:       []string{"  hello wo", "rld\tand", " ", "  more "},
+	split:    bufio.ScanWords,
+	want:     []string{"hello", "world", "and", "more"},
+}, {
+	testName: "LinesNoFinalNewline",
+	in:       []string{"one\r\ntw", "o\nthree"},
+	split:    bufio.ScanLines,
+	want:     []string{"one", "two", "three"},
+}, {
+	testName: "FinalToken",
+	in:       []string{"a,b,", "STOP,c"},
+	split:    commaSplit,
+	want:     []string{"a", "b", "STOP"},
+}, {
+	testName: "SplitError",
+	in:       []string{"a,b,", "ERR,c"},
+	split:    commaSplit,
+	want:     []string{"a", "b"},
+	wantErr:  "bad token",
+}}
+
+func TestSplitFuncSeq(t *testing.T) {
+	for _, test := range splitFuncSeqTests {
+		t.Run(test.testName, func(t *testing.T) {
+			got, err := collectStrings(SplitFuncSeq(seqOfStrings(test.in...), test.split))
+			if !slices.Equal(got, test.want) {
+				t.Errorf("unexpected results;\ngot %q\nwant %q", got, test.want)
+			}
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("unexpected error; got %v want %q", err, test.wantErr)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSplitFuncSeqMatchesScanner(t *testing.T) {
+	text := strings.Repeat("some words\n\nand a longer line of text\n", 20)
+	var want []string
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		want = append(want, scanner.Text())
+	}
+	for _, chunkSize := range []int{1, 2, 3, 7, 64, len(text)} {
+		t.Run(fmt.Sprint(chunkSize), func(t *testing.T) {
+			var chunks []string
+			for c := range slices.Chunk([]byte(text), chunkSize) {
+				chunks = append(chunks, string(c))
+			}
+			got, err := collectStrings(SplitFuncSeq(seqOfStrings(chunks...), bufio.ScanWords))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+			}
+		})
+	}
+}
+
+// commaSplit is a split function that splits on commas,
+// returning bufio.ErrFinalToken when it sees the token "STOP" and an
+// error when it sees the token "ERR".
+func commaSplit(data []byte, atEOF bool) (int, []byte, error) {
+	i := bytes.IndexByte(data, ',')
+	if i < 0 {
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+	token := data[:i]
+	switch string(token) {
+	case "STOP":
+		return i + 1, token, bufio.ErrFinalToken
+	case "ERR":
+		return 0, nil, errors.New("bad token")
+	}
+	return i + 1, token, nil
+}
+
+// seqOfStrings returns a Seq that yields each of the
+// given strings in turn.
+func seqOfStrings(ss ...string) Seq {
+	return func(yield func([]byte, error) bool) {
+		for _, s := range ss {
+			if !yield([]byte(s), nil) {
+				return
+			}
+		}
+	}
+}
+
+// collectStrings returns all the elements of seq as strings,
+// and the error that terminated it, if any.
+func collectStrings(seq Seq) ([]string, error) {
+	var got []string
+	for data, err := range seq {
+		if err != nil {
+			return got, err
+		}
+		got = append(got, string(data))
+	}
+	return got, nil
+}
@@ -0,0 +1,100 @@
+package ioseq
+
+import (
+	"math/bits"
+	"time"
+)
+
+// Stats holds statistics about the data yielded by a sequence.
+// See [StatsSeq].
+type Stats struct {
+	// Bytes holds the total number of bytes yielded.
+	Bytes int64
+
+	// Chunks holds the number of non-error elements yielded.
+	Chunks int64
+
+	// MinChunk and MaxChunk hold the sizes of the smallest
+	// and largest chunks.
+	MinChunk, MaxChunk int
+
+	// SizeHistogram holds counts of chunk sizes, bucketed
+	// by powers of two: SizeHistogram[0] counts empty chunks,
+	// and SizeHistogram[i] for i > 0 counts chunks with
+	// sizes in the range [2^(i-1), 2^i).
+	SizeHistogram [65]int64
+
+	// Elapsed holds the time from the start of the iteration
+	// until it finished.
+	Elapsed time.Duration
+
+	// Err holds the error that terminated the sequence, if any.
+	Err error
+}
+
+// MeanChunk returns the mean chunk size.
+func (s *Stats) MeanChunk() float64 {
+	if s.Chunks == 0 {
+		return 0
+	}
+	return float64(s.Bytes) / float64(s.Chunks)
+}
+
+func (s *Stats) add(n int) {
+	if s.Chunks == 0 || n < s.MinChunk {
+		s.MinChunk = n
+	}
+	s.MaxChunk = max(s.MaxChunk, n)
+	s.Bytes += int64(n)
+	s.Chunks++
+	s.SizeHistogram[bits.Len(uint(n))]++
+}
+
+// StatsSeq returns a [Seq] that yields the same elements as seq,
+// recording statistics about them in *stats. The statistics are reset
+// when iteration starts and are complete when it finishes, whether
+// because seq ended or because the consumer stopped early.
+func StatsSeq(seq Seq, stats *Stats) Seq {
+	return func(yield func([]byte, error) bool) {
+		*stats = Stats{}
+		start := time.Now()
+		defer func() {
+			stats.Elapsed = time.Since(start)
+		}()
+		for data, err := range seq {
+			if err != nil {
+				stats.Err = err
+			} else {
+				stats.add(len(data))
+			}
+			if !yield(data, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ProgressSeq returns a [Seq] that yields the same elements as seq,
+// calling fn with the cumulative number of bytes yielded so far
+// at most once in each interval of the given duration, and once more
+// when iteration finishes. fn is called synchronously from within
+// the iteration, so it should return quickly.
+func ProgressSeq(seq Seq, every time.Duration, fn func(bytes int64)) Seq {
+	return func(yield func([]byte, error) bool) {
+		var total int64
+		defer func() {
+			fn(total)
+		}()
+		last := time.Now()
+		for data, err := range seq {
+			if !yield(data, err) || err != nil {
+				return
+			}
+			total += int64(len(data))
+			if now := time.Now(); now.Sub(last) >= every {
+				last = now
+				fn(total)
+			}
+		}
+	}
+}
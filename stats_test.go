@@ -0,0 +1,68 @@
+package ioseq
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestStatsSeq(t *testing.T) {
+	var stats Stats
+	seq := StatsSeq(seqOfStrings("a", "", "abcd", "ab"), &stats)
+	if _, err := collectStrings(seq); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Bytes != 7 || stats.Chunks != 4 {
+		t.Errorf("unexpected counts; got %d bytes, %d chunks", stats.Bytes, stats.Chunks)
+	}
+	if stats.MinChunk != 0 || stats.MaxChunk != 4 {
+		t.Errorf("unexpected min/max; got %d, %d", stats.MinChunk, stats.MaxChunk)
+	}
+	if got, want := stats.MeanChunk(), 1.75; got != want {
+		t.Errorf("unexpected mean; got %v want %v", got, want)
+	}
+	if got, want := stats.SizeHistogram[:4], []int64{1, 1, 1, 1}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("unexpected histogram; got %v want %v", got, want)
+	}
+}
+
+func TestStatsSeqError(t *testing.T) {
+	in := func(yield func([]byte, error) bool) {
+		if !yield([]byte("abc"), nil) {
+			return
+		}
+		yield(nil, fmt.Errorf("some error"))
+	}
+	var stats Stats
+	collectStrings(StatsSeq(in, &stats))
+	if stats.Bytes != 3 || stats.Chunks != 1 {
+		t.Errorf("unexpected counts; got %d bytes, %d chunks", stats.Bytes, stats.Chunks)
+	}
+	if stats.Err == nil || stats.Err.Error() != "some error" {
+		t.Errorf("unexpected error %v", stats.Err)
+	}
+}
+
+func TestProgressSeq(t *testing.T) {
+	var reports []int64
+	seq := ProgressSeq(seqOfStrings("a", "bc", "def"), 0, func(n int64) {
+		reports = append(reports, n)
+	})
+	if _, err := collectStrings(seq); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(reports), "[1 3 6 6]"; got != want {
+		t.Errorf("unexpected reports; got %v want %v", got, want)
+	}
+
+	reports = nil
+	seq = ProgressSeq(seqOfStrings("a", "bc", "def"), time.Hour, func(n int64) {
+		reports = append(reports, n)
+	})
+	if _, err := collectStrings(seq); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(reports), "[6]"; got != want {
+		t.Errorf("unexpected reports; got %v want %v", got, want)
+	}
+}
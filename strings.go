@@ -0,0 +1,78 @@
+package ioseq
+
+import (
+	"iter"
+	"unsafe"
+)
+
+// StringSeq returns a sequence that yields each chunk of seq as a
+// string. Because strings are immutable and may be retained, each
+// chunk is copied. See [StringViewSeq] for a form that avoids the copy.
+func StringSeq(seq Seq) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for data, err := range seq {
+			if !yield(string(data), err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// StringViewSeq is like [StringSeq] except that each string shares
+// its memory with the chunk yielded by seq rather than being a copy.
+// Unlike other strings, a yielded string is valid only until the next
+// iteration: the producer may then overwrite the chunk, changing the
+// contents of the string. The consumer must copy the string (for
+// example with [strings.Clone]) to retain it.
+func StringViewSeq(seq Seq) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for data, err := range seq {
+			if !yield(unsafe.String(unsafe.SliceData(data), len(data)), err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// SeqFromStringSeq returns a [Seq] that yields each string in ss as a
+// chunk. Following the usual rule that consumers must not modify
+// the yielded slices, the strings are not copied. The yielded slices
+// may refer to read-only memory, such as that of a string constant,
+// so a consumer that modifies one may crash the program.
+func SeqFromStringSeq(ss iter.Seq2[string, error]) Seq {
+	return func(yield func([]byte, error) bool) {
+		for s, err := range ss {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(stringBytes(s), nil) {
+				return
+			}
+		}
+	}
+}
+
+// StringsSeq returns a [Seq] that yields each element of ss in turn.
+// As with [SeqFromStringSeq], the strings are not copied, and the
+// yielded slices may refer to read-only memory that must not be
+// modified.
+func StringsSeq(ss []string) Seq {
+	return func(yield func([]byte, error) bool) {
+		for _, s := range ss {
+			if !yield(stringBytes(s), nil) {
+				return
+			}
+		}
+	}
+}
+
+// stringBytes returns the bytes of s without copying them.
+// The result must not be modified.
+func stringBytes(s string) []byte {
+	if s == "" {
+		// unsafe.StringData is unspecified for empty strings.
+		return []byte{}
+	}
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
@@ -0,0 +1,75 @@
+package ioseq
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestStringSeq(t *testing.T) {
+	var got []string
+	for s, err := range StringSeq(reusingSeq("foo", "bar")) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, s)
+	}
+	if want := []string{"foo", "bar"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestStringViewSeq(t *testing.T) {
+	seqErr := fmt.Errorf("some error")
+	var got []string
+	var gotErr error
+	for s, err := range StringViewSeq(ConcatSeqs(reusingSeq("foo", "", "bar"), ErrorSeq(seqErr))) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		got = append(got, strings.Clone(s))
+	}
+	if want := []string{"foo", "", "bar"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if gotErr != seqErr {
+		t.Errorf("unexpected error %v", gotErr)
+	}
+
+	// The strings share memory with the chunks.
+	buf := []byte("hello")
+	for s := range StringViewSeq(SeqFromBytes(buf)) {
+		buf[0] = 'j'
+		if s != "jello" {
+			t.Errorf("string does not share memory with chunk; got %q", s)
+		}
+	}
+}
+
+func TestSeqFromStringSeq(t *testing.T) {
+	ss := func(yield func(string, error) bool) {
+		if !yield("foo", nil) || !yield("", nil) {
+			return
+		}
+		yield("", fmt.Errorf("some error"))
+	}
+	got, err := collectStrings(SeqFromStringSeq(ss))
+	if want := []string{"foo", ""}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if err == nil || err.Error() != "some error" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestStringsSeq(t *testing.T) {
+	got, err := collectStrings(StringsSeq([]string{"a", "bc"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "bc"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+}
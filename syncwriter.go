@@ -0,0 +1,58 @@
+package ioseq
+
+import (
+	"io"
+	"sync"
+)
+
+// SyncSeqWriter is like [SeqWriter] except that the returned writer
+// is safe for concurrent use by multiple goroutines. Each Write is
+// yielded as a single element, so data from separate writes is never
+// interleaved within an element, although the order of writes from
+// different goroutines is unspecified.
+//
+// The yield function is called on the goroutine that calls Write.
+// This is fine for iteration with a range loop or with [iter.Pull2],
+// but the iterator function must not return until all writes have
+// finished: calling yield after that is a run-time error. Calling
+// Close before returning ensures that any subsequent writes fail with
+// [ErrSequenceTerminated] instead.
+//
+// The caller must not access *active while writes may be in progress.
+//
+// The returned Writer implements [SeqWriteCloser].
+func SyncSeqWriter(yield func([]byte, error) bool, active *bool) io.Writer {
+	return &syncSeqWriter{
+		w: SeqWriter(yield, active).(seqWriter),
+	}
+}
+
+type syncSeqWriter struct {
+	mu sync.Mutex
+	w  seqWriter
+}
+
+func (w *syncSeqWriter) Write(buf []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(buf)
+}
+
+// WriteString implements [io.StringWriter].
+func (w *syncSeqWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.WriteString(s)
+}
+
+// Close implements [SeqWriteCloser.Close].
+func (w *syncSeqWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+// CloseWithError implements [SeqWriteCloser.CloseWithError].
+func (w *syncSeqWriter) CloseWithError(err error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.CloseWithError(err)
+}
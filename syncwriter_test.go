@@ -0,0 +1,60 @@
+package ioseq
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestSyncSeqWriter(t *testing.T) {
+	const n = 20
+	seq := func(yield func([]byte, error) bool) {
+		w := SyncSeqWriter(yield, nil)
+		defer w.(io.Closer).Close()
+		var wg sync.WaitGroup
+		for i := range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				fmt.Fprintf(w, "%02d", i)
+			}()
+		}
+		wg.Wait()
+	}
+	var want []string
+	for i := range n {
+		want = append(want, fmt.Sprintf("%02d", i))
+	}
+	got, err := collectStrings(seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+
+	// Check that it works with iter.Pull2 too.
+	data, err := io.ReadAll(ReaderFromSeq(seq))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(data), 2*n; got != want {
+		t.Errorf("unexpected data length; got %d want %d", got, want)
+	}
+}
+
+func TestSyncSeqWriterWriteAfterClose(t *testing.T) {
+	seq := func(yield func([]byte, error) bool) {
+		w := SyncSeqWriter(yield, nil)
+		w.(io.Closer).Close()
+		if _, err := io.WriteString(w, "x"); err != ErrSequenceTerminated {
+			t.Errorf("unexpected error; got %v want %v", err, ErrSequenceTerminated)
+		}
+	}
+	for range seq {
+		t.Errorf("unexpected element")
+	}
+}
@@ -0,0 +1,149 @@
+package ioseq
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrStalled is yielded by a sequence returned from [StallTimeoutSeq]
+// when the underlying sequence fails to produce an element in time.
+var ErrStalled = errors.New("sequence stalled")
+
+// StallTimeoutSeq returns a [Seq] that yields the same elements as
+// seq, but yields [ErrStalled] and stops if seq takes longer than
+// timeout to produce any element. The timeout applies to each element
+// separately, measured from when the consumer asks for it (that is,
+// from when the previous iteration of the loop ended), so a slow
+// consumer does not cause a timeout.
+//
+// To do this, seq is run in a separate goroutine. If seq stalls, that
+// goroutine remains blocked until seq produces its next element or
+// returns, at which point it stops.
+func StallTimeoutSeq(seq Seq, timeout time.Duration) Seq {
+	return func(yield func([]byte, error) bool) {
+		p := startPump(seq)
+		// Stopping the pump stops the goroutine.
+		defer p.stop()
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		for {
+			select {
+			case it, ok := <-p.items:
+				if !ok {
+					return
+				}
+				if !yield(it.data, it.err) || it.err != nil {
+					return
+				}
+				timer.Reset(timeout)
+				p.next()
+			case <-timer.C:
+				yield(nil, ErrStalled)
+				return
+			}
+		}
+	}
+}
+
+// KeepaliveSeq returns a [Seq] that yields the same elements as seq,
+// calling keepalive whenever seq has produced nothing for the given
+// interval. This is useful for streams that can legitimately go quiet
+// for a long time, where an idle connection might otherwise be closed
+// by a proxy. If keepalive returns a non-nil slice, it is yielded as
+// if it were part of the data (for example, padding or a comment in a
+// format that allows it); otherwise keepalive is called only for its
+// side effects, such as pinging a connection.
+//
+// As with [StallTimeoutSeq], the interval is measured from when the
+// consumer asks for the next element, and seq is run in a separate
+// goroutine. keepalive is called on the consumer's goroutine.
+func KeepaliveSeq(seq Seq, interval time.Duration, keepalive func() []byte) Seq {
+	return func(yield func([]byte, error) bool) {
+		p := startPump(seq)
+		defer p.stop()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case it, ok := <-p.items:
+				if !ok {
+					return
+				}
+				if !yield(it.data, it.err) || it.err != nil {
+					return
+				}
+				ticker.Reset(interval)
+				p.next()
+			case <-ticker.C:
+				if data := keepalive(); data != nil && !yield(data, nil) {
+					return
+				}
+				ticker.Reset(interval)
+			}
+		}
+	}
+}
+
+// pumpItem holds an element of a sequence.
+type pumpItem struct {
+	data []byte
+	err  error
+}
+
+// seqPump runs a sequence in a separate goroutine, handing its
+// elements over one at a time so that the consumer can wait for
+// them alongside other events.
+type seqPump struct {
+	// items receives each element of the sequence in turn, and
+	// is closed when the sequence has finished.
+	items chan pumpItem
+
+	// acks receives a value when the consumer has finished
+	// with an item and wants another one.
+	acks chan struct{}
+
+	// done is closed when the consumer has finished.
+	done chan struct{}
+}
+
+// startPump starts a goroutine iterating over seq. After receiving
+// an item from p.items, the caller must call p.next to ask for
+// another one, and it must call p.stop when it has finished.
+//
+// If seq is blocked when p.stop is called, the goroutine remains
+// blocked until seq produces its next element or returns, at which
+// point it stops.
+func startPump(seq Seq) *seqPump {
+	p := &seqPump{
+		items: make(chan pumpItem),
+		acks:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(p.items)
+		for data, err := range seq {
+			select {
+			case p.items <- pumpItem{data, err}:
+			case <-p.done:
+				return
+			}
+			select {
+			case <-p.acks:
+			case <-p.done:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// next tells the goroutine that the consumer has finished
+// with the current item and wants another one.
+func (p *seqPump) next() {
+	p.acks <- struct{}{}
+}
+
+// stop stops the goroutine.
+func (p *seqPump) stop() {
+	close(p.done)
+}
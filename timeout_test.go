@@ -0,0 +1,93 @@
+package ioseq
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStallTimeoutSeq(t *testing.T) {
+	release := make(chan struct{})
+	in := func(yield func([]byte, error) bool) {
+		if !yield([]byte("a"), nil) {
+			return
+		}
+		if !yield([]byte("b"), nil) {
+			return
+		}
+		<-release
+		yield([]byte("c"), nil)
+	}
+	defer close(release)
+	var got []string
+	var gotErr error
+	for data, err := range StallTimeoutSeq(in, 50*time.Millisecond) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		got = append(got, string(data))
+		// A slow consumer should not trigger the timeout.
+		time.Sleep(75 * time.Millisecond)
+	}
+	if want := []string{"a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	if gotErr != ErrStalled {
+		t.Errorf("unexpected error %v", gotErr)
+	}
+}
+
+func TestStallTimeoutSeqNoStall(t *testing.T) {
+	got, err := collectStrings(StallTimeoutSeq(seqOfStrings("a", "b", "c"), time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+	for range StallTimeoutSeq(seqOfStrings("a", "b", "c"), time.Minute) {
+		break
+	}
+}
+
+func TestKeepaliveSeq(t *testing.T) {
+	release := make(chan struct{})
+	in := func(yield func([]byte, error) bool) {
+		if !yield([]byte("a"), nil) {
+			return
+		}
+		<-release
+		yield([]byte("b"), nil)
+	}
+	calls := 0
+	got, err := collectStrings(KeepaliveSeq(in, time.Millisecond, func() []byte {
+		calls++
+		switch calls {
+		case 1:
+			return []byte(".")
+		case 2:
+			close(release)
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", ".", "b"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected result; got %q want %q", got, want)
+	}
+}
+
+func TestKeepaliveSeqStop(t *testing.T) {
+	in := func(yield func([]byte, error) bool) {
+		for {
+			if !yield([]byte("a"), nil) {
+				return
+			}
+		}
+	}
+	for range KeepaliveSeq(in, time.Hour, func() []byte { return nil }) {
+		break
+	}
+}
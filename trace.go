@@ -0,0 +1,68 @@
+package ioseq
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Tracer is implemented by types that trace the running of the stages
+// of a [Pipeline], such as the OpenTelemetry adapter in package
+// github.com/rogpeppe/ioseq/otelseq. See [Pipeline.WithTracer].
+//
+// Methods may be called concurrently for different stages.
+type Tracer interface {
+	// StartStage is called when the pipeline starts to run the
+	// named stage. It returns the context to be used by the stage,
+	// which will usually hold a span derived from ctx, so that
+	// any work done by the stage is traced as part of it.
+	//
+	// The returned function is called exactly once, when the
+	// iteration over the stage's output finishes, with statistics
+	// about the output, including any error. If the output is never
+	// iterated over, it's called with zero statistics when
+	// [Pipeline.Run] returns. For the sink, the statistics are those
+	// of the data it consumed and the error is the one it returned.
+	StartStage(ctx context.Context, stage string) (context.Context, func(stats *Stats))
+}
+
+// stageTrace holds the tracing state for a single stage.
+type stageTrace struct {
+	end  func(*Stats)
+	done atomic.Bool
+}
+
+// startStage starts tracing the named stage if the pipeline has a
+// tracer. It returns the context for the stage and the trace, which is
+// nil if there is no tracer.
+func (p *Pipeline) startStage(ctx context.Context, name string) (context.Context, *stageTrace) {
+	if p.tracer == nil {
+		return ctx, nil
+	}
+	ctx, end := p.tracer.StartStage(ctx, name)
+	return ctx, &stageTrace{end: end}
+}
+
+// seq returns a sequence that yields the same elements as seq, calling
+// t.end with statistics about them when the iteration finishes.
+func (t *stageTrace) seq(seq Seq) Seq {
+	if t == nil {
+		return seq
+	}
+	return func(yield func([]byte, error) bool) {
+		if !t.done.CompareAndSwap(false, true) {
+			seq(yield)
+			return
+		}
+		var stats Stats
+		defer t.end(&stats)
+		StatsSeq(seq, &stats)(yield)
+	}
+}
+
+// finish calls t.end with the given statistics
+// if it has not already been called.
+func (t *stageTrace) finish(stats *Stats) {
+	if t != nil && t.done.CompareAndSwap(false, true) {
+		t.end(stats)
+	}
+}
@@ -0,0 +1,98 @@
+package ioseq
+
+import "slices"
+
+// MapSeq returns a [Seq] that yields the result of calling f on each
+// chunk of seq. Errors are passed through unchanged.
+//
+// As with any consumer, f must not modify or retain the slice passed
+// to it. The slice returned by f is yielded to the consumer, which in
+// turn must not modify or retain it, so f may return a subslice of its
+// argument or reuse the same buffer on each call. If f returns nil,
+// nothing is yielded for that chunk.
+func MapSeq(seq Seq, f func([]byte) []byte) Seq {
+	return func(yield func([]byte, error) bool) {
+		for data, err := range seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			data = f(data)
+			if data == nil {
+				continue
+			}
+			if !yield(data, nil) {
+				return
+			}
+		}
+	}
+}
+
+// FilterSeq returns a [Seq] that yields only the chunks of seq for
+// which f returns true. Errors are always passed through. f must not
+// modify or retain the slice passed to it.
+func FilterSeq(seq Seq, f func([]byte) bool) Seq {
+	return func(yield func([]byte, error) bool) {
+		for data, err := range seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if f(data) && !yield(data, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Transform represents a transformation of one sequence into another,
+// such as a decompression or filtering stage.
+type Transform func(Seq) Seq
+
+// Chain returns a [Transform] that applies each of ts in turn,
+// so that Chain(a, b)(seq) is equivalent to b(a(seq)).
+func Chain(ts ...Transform) Transform {
+	ts = slices.Clone(ts)
+	return func(seq Seq) Seq {
+		for _, t := range ts {
+			seq = t(seq)
+		}
+		return seq
+	}
+}
+
+// Stage is a [Transform] with a name and optional metadata, which
+// makes it possible to inspect and report on a chain of stages.
+type Stage struct {
+	Name      string
+	Transform Transform
+
+	// Attrs holds arbitrary metadata about the stage, for example
+	// for use in logging or metrics.
+	Attrs map[string]string
+}
+
+// Stages represents a sequence of named stages, applied in order.
+type Stages []Stage
+
+// Names returns the names of the stages in order.
+func (ss Stages) Names() []string {
+	names := make([]string, len(ss))
+	for i, s := range ss {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Transform returns a [Transform] that applies all the stages in
+// order. Any error yielded by a stage that did not come from an
+// earlier stage is returned as a [*StageError] naming it.
+func (ss Stages) Transform() Transform {
+	ss = slices.Clone(ss)
+	return func(seq Seq) Seq {
+		for _, s := range ss {
+			seq = attributeErrors(s.Name, s.Transform(seq))
+		}
+		return seq
+	}
+}
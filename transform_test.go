@@ -0,0 +1,87 @@
+package ioseq
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestMapSeq(t *testing.T) {
+	var buf []byte
+	upper := func(data []byte) []byte {
+		if bytes.Equal(data, []byte("skip")) {
+			return nil
+		}
+		// Reuse the same buffer each time.
+		buf = append(buf[:0], bytes.ToUpper(data)...)
+		return buf
+	}
+	got, err := collectStrings(MapSeq(seqOfStrings("foo", "skip", "bar"), upper))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"FOO", "BAR"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestFilterSeq(t *testing.T) {
+	nonEmpty := func(data []byte) bool {
+		return len(data) > 0
+	}
+	got, err := collectStrings(FilterSeq(seqOfStrings("foo", "", "bar", ""), nonEmpty))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"foo", "bar"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected results;\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestChain(t *testing.T) {
+	upper := func(seq Seq) Seq {
+		return MapSeq(seq, bytes.ToUpper)
+	}
+	exclaim := func(seq Seq) Seq {
+		return ConcatSeqs(seq, SeqFromString("!"))
+	}
+	got, err := ReadAllSeq(Chain(upper, exclaim)(seqOfStrings("hello")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(got), "HELLO!"; got != want {
+		t.Errorf("unexpected result; got %q want %q", got, want)
+	}
+}
+
+func TestStages(t *testing.T) {
+	stageErr := errors.New("stage failure")
+	stages := Stages{{
+		Name: "upper",
+		Transform: func(seq Seq) Seq {
+			return MapSeq(seq, bytes.ToUpper)
+		},
+	}, {
+		Name: "fail",
+		Transform: func(seq Seq) Seq {
+			return ConcatSeqs(seq, ErrorSeq(stageErr))
+		},
+	}, {
+		Name: "passthrough",
+		Transform: func(seq Seq) Seq {
+			return seq
+		},
+	}}
+	if got, want := stages.Names(), []string{"upper", "fail", "passthrough"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected names; got %q want %q", got, want)
+	}
+	got, err := ReadAllSeq(stages.Transform()(seqOfStrings("hello")))
+	if string(got) != "HELLO" {
+		t.Errorf("unexpected data %q", got)
+	}
+	var serr *StageError
+	if !errors.As(err, &serr) || serr.Stage != "fail" || serr.Err != stageErr {
+		t.Errorf("unexpected error %v", err)
+	}
+}
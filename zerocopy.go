@@ -0,0 +1,83 @@
+package ioseq
+
+import (
+	"io"
+	"os"
+)
+
+// ReaderSeq represents a sequence that reads directly from Reader,
+// with no transformation of the data.
+//
+// Because a [Seq] is just a function, there is no way to tell from a
+// Seq where its data comes from, so copying from a Seq always moves
+// the data through user-space buffers, even when the data is read
+// untransformed from a file and written to a network connection.
+// ReaderSeq keeps the source reader available so that consumers that
+// know about it, such as [Copy] and the helpers in the httpseq
+// package, can use [io.Copy] instead, which takes advantage of
+// operating system zero-copy mechanisms such as sendfile(2) and
+// splice(2) when copying between files and sockets.
+type ReaderSeq struct {
+	Reader io.Reader
+
+	// BufSize holds the buffer size to use when reading
+	// from Reader as a Seq, as for [SeqFromReader].
+	// If it's zero, [DefaultBufferSize] is used.
+	BufSize int
+}
+
+// Seq returns the data from s.Reader as a [Seq].
+func (s ReaderSeq) Seq() Seq {
+	bufSize := s.BufSize
+	if bufSize <= 0 {
+		bufSize = DefaultBufferSize
+	}
+	return SeqFromReader(s.Reader, bufSize)
+}
+
+// Size returns the number of bytes remaining in s.Reader if that can
+// be determined without reading it: when the reader has a Len method,
+// as [bytes.Reader] and [strings.Reader] do, or is an [*os.File]
+// holding a regular file. Otherwise it returns -1.
+func (s ReaderSeq) Size() int64 {
+	switch r := s.Reader.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		off, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return max(info.Size()-off, 0)
+	}
+	return -1
+}
+
+// CopyTo copies all the data from s.Reader to w using [io.Copy],
+// so the copy is done without user-space buffering when the
+// operating system supports it for the given reader and writer.
+func (s ReaderSeq) CopyTo(w io.Writer) (int64, error) {
+	return io.Copy(w, s.Reader)
+}
+
+// Source is the set of types accepted by [Copy].
+type Source interface {
+	Seq | ReaderSeq
+}
+
+// Copy copies all the data from src to w. If src is a [ReaderSeq], it
+// uses [ReaderSeq.CopyTo], so that the operating system's zero-copy
+// mechanisms can be used; otherwise it is equivalent to [CopySeq].
+func Copy[S Source](w io.Writer, src S) (int64, error) {
+	switch src := any(src).(type) {
+	case ReaderSeq:
+		return src.CopyTo(w)
+	case Seq:
+		return CopySeq(w, src)
+	}
+	panic("unreachable")
+}
@@ -0,0 +1,159 @@
+package ioseq
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReaderSeq(t *testing.T) {
+	s := ReaderSeq{
+		Reader:  strings.NewReader("hello world"),
+		BufSize: 4,
+	}
+	data, err := ReadAllSeq(s.Seq())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "hello world"; got != want {
+		t.Errorf("unexpected data; got %q want %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	s.Reader = strings.NewReader("goodbye")
+	n, err := s.CopyTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 7 || buf.String() != "goodbye" {
+		t.Errorf("unexpected result %d, %q", n, buf.String())
+	}
+}
+
+// readerFromWriter records whether its ReadFrom method was used.
+type readerFromWriter struct {
+	bytes.Buffer
+	usedReadFrom bool
+}
+
+func (w *readerFromWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.usedReadFrom = true
+	return w.Buffer.ReadFrom(r)
+}
+
+func TestCopy(t *testing.T) {
+	var w readerFromWriter
+	// Hide the WriteTo method so that io.Copy uses ReadFrom.
+	n, err := Copy(&w, ReaderSeq{Reader: struct{ io.Reader }{strings.NewReader("hello")}})
+	if err != nil || n != 5 || w.String() != "hello" {
+		t.Errorf("unexpected result %d, %v, %q", n, err, w.String())
+	}
+	if !w.usedReadFrom {
+		t.Errorf("ReadFrom not used for ReaderSeq")
+	}
+
+	w = readerFromWriter{}
+	n, err = Copy(&w, seqOfStrings("hello"))
+	if err != nil || n != 5 || w.String() != "hello" {
+		t.Errorf("unexpected result %d, %v, %q", n, err, w.String())
+	}
+	if w.usedReadFrom {
+		t.Errorf("ReadFrom unexpectedly used for Seq")
+	}
+}
+
+func TestReaderSeqSize(t *testing.T) {
+	if got := (ReaderSeq{Reader: strings.NewReader("hello")}).Size(); got != 5 {
+		t.Errorf("unexpected size for strings.Reader: %d", got)
+	}
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, []byte("hello world"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Read(make([]byte, 6)); err != nil {
+		t.Fatal(err)
+	}
+	if got := (ReaderSeq{Reader: f}).Size(); got != 5 {
+		t.Errorf("unexpected size for file: %d", got)
+	}
+	if got := (ReaderSeq{Reader: io.MultiReader()}).Size(); got != -1 {
+		t.Errorf("unexpected size for unknown reader: %d", got)
+	}
+}
+
+// BenchmarkFileToSocket compares copying a file to a TCP connection
+// through a Seq with copying it with Copy from a ReaderSeq, which can
+// use sendfile(2).
+func BenchmarkFileToSocket(b *testing.B) {
+	const size = 64 << 20
+	path := filepath.Join(b.TempDir(), "data")
+	if err := os.WriteFile(path, bytes.Repeat([]byte("x"), size), 0o666); err != nil {
+		b.Fatal(err)
+	}
+	bench := func(b *testing.B, copyFile func(w io.Writer, f *os.File) error) {
+		conn, done := discardConn(b)
+		defer func() {
+			conn.Close()
+			<-done
+		}()
+		b.SetBytes(size)
+		for b.Loop() {
+			f, err := os.Open(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := copyFile(conn, f); err != nil {
+				b.Fatal(err)
+			}
+			f.Close()
+		}
+	}
+	b.Run("kind=seq", func(b *testing.B) {
+		bench(b, func(w io.Writer, f *os.File) error {
+			_, err := CopySeq(w, SeqFromReader(f, DefaultBufferSize))
+			return err
+		})
+	})
+	b.Run("kind=readerseq", func(b *testing.B) {
+		bench(b, func(w io.Writer, f *os.File) error {
+			_, err := Copy(w, ReaderSeq{Reader: f})
+			return err
+		})
+	})
+}
+
+// discardConn returns a TCP connection whose peer discards
+// everything written to it. The returned channel is closed
+// when the peer has finished.
+func discardConn(b *testing.B) (net.Conn, <-chan struct{}) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer lis.Close()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c, err := lis.Accept()
+		if err != nil {
+			b.Error(err)
+			return
+		}
+		defer c.Close()
+		io.Copy(io.Discard, c)
+	}()
+	conn, err := net.Dial("tcp", lis.Addr().String())
+	if err != nil {
+		b.Fatal(err)
+	}
+	return conn, done
+}